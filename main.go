@@ -2,54 +2,118 @@ package main
 
 import (
 	"bytes"
+	"flag"
 	"fmt"
-	"image/color"
+	"os"
+	"time"
 
-	"github.com/EngoEngine/ecs"
 	"github.com/EngoEngine/engo"
-	"github.com/EngoEngine/engo/common"
 	"github.com/yamazaki-ko/SuperMario/systems"
 	"golang.org/x/image/font/gofont/gosmallcaps"
 )
 
-type myScene struct{}
+type myScene struct {
+	// 現在のコース（コースをやり直しても同じものを使う）
+	course *systems.GameCourse
+	// スコア、コイン、残り人数、選べるコース（コースをやり直しても引き継ぐ）
+	progress *systems.Progress
+	// ボタンの割り当て
+	bindings systems.Bindings
+	// 入力元（コースをやり直しても同じものを使う）
+	source systems.InputSource
+	// 入力を記録するファイル
+	recordFile string
+	// 入力の記録
+	recorder *systems.RecordingInput
+}
 
 func (*myScene) Type() string { return "myGame" }
 
 // Preload is called before loading any assets from the disk,
 // to allow you to register / queue them
-func (*myScene) Preload() {
-	engo.Files.Load("./Mario/Characters/Mario.png")
-	engo.Files.Load("./Mario/Characters/Enemies.png")
-	engo.Files.Load("./assets/Mario/Misc/Items.png")
-	engo.Files.Load("./Mario/Tilesets/OverWorld.png")
-	engo.Files.Load("./Mario/Tilesets/Castle.png")
-	common.SetBackground(color.RGBA{120, 226, 250, 3})
+func (scene *myScene) Preload() {
+	systems.LoadGameFiles(scene.course.CourseLevelFile)
 }
 
 // Setup is called before the main loop starts.
 // It allows you to add entities and systems to your Scene.
-func (*myScene) Setup(u engo.Updater) {
-	// キーボード設定
-	engo.Input.RegisterButton("MoveRight", engo.KeyD, engo.KeyArrowRight)
-	engo.Input.RegisterButton("MoveLeft", engo.KeyA, engo.KeyArrowLeft)
-	engo.Input.RegisterButton("Jump", engo.KeySpace)
-	engo.Input.RegisterButton("Enter", engo.KeyEnter)
+func (scene *myScene) Setup(u engo.Updater) {
+	// キーボード、ゲームパッド設定
+	scene.bindings.Register()
+	if engo.Input.Gamepad(systems.GamepadName) == nil {
+		if err := engo.Input.RegisterGamepad(systems.GamepadName); err != nil {
+			fmt.Println("Unable to use gamepad: " + err.Error())
+		}
+	}
 	// フォント設定
 	engo.Files.LoadReaderData("go.ttf", bytes.NewReader(gosmallcaps.TTF))
 
-	// World設定
-	world, _ := u.(*ecs.World)
+	// コースファイルが読み込めない場合は、ランダムなコースで代わりに始めずに終了する
+	if levelFile := scene.course.CourseLevelFile; levelFile != "" {
+		if _, err := systems.LoadLevelFile(levelFile); err != nil {
+			fmt.Println("Unable to start level: " + levelFile + "：" + err.Error())
+			// 遊んでいないので入力の記録も保存しない
+			scene.recorder = nil
+			engo.Exit()
+			return
+		}
+	}
+
+	// World設定（ゲームの処理は固定ステップで更新する）
+	world, _ := u.(*systems.FixedStepWorld)
+
+	// ゲームの状態（Worldごとに作成して各Systemに渡す）
+	game := scene.course.NewGameState(scene.progress)
 
 	// Systemの追加
-	world.AddSystem(&common.RenderSystem{})
-	world.AddSystem(&systems.TileSystem{})
-	world.AddSystem(&systems.PlayerSystem{})
-	world.AddSystem(&systems.EnermySystem{})
-	world.AddSystem(&systems.HUDTextSystem{})
+	systems.AddGameSystems(world, game, scene.source, scene.course.CourseSeed, scene.course.CourseLevelFile)
+
+	// コースのやり直し（Worldを作り直す）
+	systems.ListenRestart(world, scene, scene.course, scene.progress)
 }
 
 func main() {
+	seed := flag.Int64("seed", 0, "seed of the course generation (0: random)")
+	levelFile := flag.String("level", "", "level file of the first course (.json or .tmx), relative to assets (empty: random course)")
+	recordFile := flag.String("record", "", "file to record the inputs of the run into, saved on exit")
+	replayFile := flag.String("replay", "", "replay file to play back (overrides -seed and -level)")
+	bindingsFile := flag.String("bindings", "bindings.json", "bindings file of the keys and the gamepad buttons (missing: default bindings)")
+	flag.Parse()
+
+	scene := &myScene{recordFile: *recordFile}
+	bindings, err := systems.LoadBindings(*bindingsFile)
+	if err != nil && !os.IsNotExist(err) {
+		fmt.Println("Unable to load bindings: " + *bindingsFile + "：" + err.Error())
+	}
+	scene.bindings = bindings
+
+	// 入力設定（リプレイの再生、入力の記録）
+	scene.source = systems.CombinedInput{
+		systems.KeyboardInput{},
+		systems.GamepadInput{Bindings: bindings},
+	}
+	if *replayFile != "" {
+		replay, err := systems.LoadReplay(*replayFile)
+		if err != nil {
+			fmt.Println("Unable to load replay: " + *replayFile + "：" + err.Error())
+			return
+		}
+		scene.source = systems.NewReplayInput(replay)
+		*seed = replay.Seed
+		*levelFile = replay.LevelFile
+	}
+	if scene.recordFile != "" {
+		scene.recorder = &systems.RecordingInput{Source: scene.source}
+		scene.source = scene.recorder
+	}
+
+	// シード設定（新しいコースのシードも最初のシードから決まるため、リプレイで再現できる）
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	scene.course = systems.NewGameCourse(*seed, *levelFile)
+	scene.progress = systems.NewProgress()
+
 	fmt.Printf("hello, world\n")
 	opts := engo.RunOptions{
 		Title:          "SuperMario",
@@ -57,11 +121,21 @@ func main() {
 		Height:         320,
 		StandardInputs: true,
 		NotResizable:   true,
+		Update:         &systems.FixedStepWorld{},
 	}
 	fmt.Println("SuperMario Start")
-	engo.Run(opts, &myScene{})
+	engo.Run(opts, scene)
 }
 
-func (*myScene) Exit() {
+func (scene *myScene) Exit() {
+	// 入力の記録を保存
+	if scene.recorder != nil {
+		replay := scene.recorder.Replay
+		replay.Seed = scene.course.Seed
+		replay.LevelFile = scene.course.LevelFile
+		if err := replay.Save(scene.recordFile); err != nil {
+			fmt.Println("Unable to save replay: " + scene.recordFile + "：" + err.Error())
+		}
+	}
 	engo.Exit()
 }
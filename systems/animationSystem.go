@@ -0,0 +1,103 @@
+package systems
+
+import (
+	"github.com/EngoEngine/engo/common"
+)
+
+const (
+	// AnimationIdle : 立ち止まる
+	AnimationIdle = "idle"
+	// AnimationWalk : 歩く
+	AnimationWalk = "walk"
+	// AnimationRun : 走る
+	AnimationRun = "run"
+	// AnimationJump : ジャンプ
+	AnimationJump = "jump"
+	// AnimationSkid : 振り向く（ブレーキ）
+	AnimationSkid = "skid"
+	// AnimationDie : 死亡
+	AnimationDie = "die"
+	// AnimationClimb : ポールにつかまる
+	AnimationClimb = "climb"
+	// AnimationChomp : パックンフラワーが口を開け閉めする
+	AnimationChomp = "chomp"
+	// AnimationFlat : つぶれたクリボー
+	AnimationFlat = "flat"
+	// AnimationShell : ノコノコの甲羅
+	AnimationShell = "shell"
+)
+
+// SpriteAnimation is an animation with the time of each of its frames
+type SpriteAnimation struct {
+	common.Animation
+	// Rate : 1コマの時間（秒）
+	Rate float32
+}
+
+// AnimationComponent is a common.AnimationComponent whose animations have their own frame time.
+// The animations are switched by name from the game logic with Play
+type AnimationComponent struct {
+	common.AnimationComponent
+	// アニメーション毎の1コマの時間（秒）
+	rates map[string]float32
+}
+
+// NewAnimationComponent creates an AnimationComponent of the cells of a sprite sheet, with its animations.
+// The first animation is the default one
+func NewAnimationComponent(spritesheet *common.Spritesheet, animations []*SpriteAnimation) AnimationComponent {
+	anim := AnimationComponent{
+		AnimationComponent: common.NewAnimationComponent(spritesheet.Drawables(), animations[0].Rate),
+		rates:              make(map[string]float32),
+	}
+	anim.AddAnimations(animations)
+	anim.AddDefaultAnimation(&animations[0].Animation)
+	return anim
+}
+
+// AddAnimations registers the animations, replacing those of the same name
+func (ac *AnimationComponent) AddAnimations(animations []*SpriteAnimation) {
+	for _, a := range animations {
+		ac.AddAnimation(&a.Animation)
+		ac.rates[a.Name] = a.Rate
+	}
+}
+
+// Playing returns the name of the current animation
+func (ac *AnimationComponent) Playing() string {
+	if ac.CurrentAnimation == nil {
+		return ""
+	}
+	return ac.CurrentAnimation.Name
+}
+
+// Play switches the animation by name, unless it is already playing, and shows its first frame at once
+func (ac *AnimationComponent) Play(render *common.RenderComponent, name string) {
+	if ac.Playing() == name {
+		return
+	}
+	ac.Restart(render, name)
+}
+
+// Restart plays the animation by name from its first frame, even if it is already playing
+func (ac *AnimationComponent) Restart(render *common.RenderComponent, name string) {
+	ac.SelectAnimationByName(name)
+	ac.Rate = ac.rates[name]
+	render.Drawable = ac.Cell()
+}
+
+// AnimationSystem advances the animations of the entities (AnimationComponent),
+// and stops them while the game is not being played (except during the goal sequence)
+type AnimationSystem struct {
+	common.AnimationSystem
+	// Game : ゲームの状態
+	Game *GameState
+}
+
+// Update is ran every frame, with `dt` being the time in seconds since the last frame
+func (as *AnimationSystem) Update(dt float32) {
+	// プレイ中、ゴール演出中でなければ止める
+	if state := as.Game.State(); state != StatePlaying && state != StateClear {
+		return
+	}
+	as.AnimationSystem.Update(dt)
+}
@@ -0,0 +1,72 @@
+package systems
+
+import (
+	"testing"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/EngoEngine/engo/common"
+)
+
+func TestAnimationTimedInSeconds(t *testing.T) {
+	sim := newTestSimulation(1, flatLevelFile)
+	sim.Start()
+	spritesheet := common.NewSpritesheetWithBorderFromFile(enermyFile, CellWidth32, CellHeight32, 0, 0)
+	basic := ecs.NewBasic()
+	render := &common.RenderComponent{}
+	anim := NewAnimationComponent(spritesheet, enermyAnimations(EneymyType1))
+	as := &AnimationSystem{Game: sim.game}
+	as.Add(&basic, &anim.AnimationComponent, render)
+
+	// 1コマの時間が経つまでは同じコマ（フレームの数ではなく秒で進む）
+	anim.Play(render, AnimationWalk)
+	as.Update(EnermyCellTime * 0.5)
+	as.Update(EnermyCellTime * 0.4)
+	if anim.Cell() != spritesheet.Cell(Type1Cell) {
+		t.Errorf("next cell before %vs", EnermyCellTime)
+	}
+	as.Update(EnermyCellTime * 0.2)
+	if anim.Cell() != spritesheet.Cell(Type1Cell+1) {
+		t.Errorf("same cell after %vs", EnermyCellTime)
+	}
+
+	// 名前で切り替えるとすぐに最初のコマを表示する
+	anim.Play(render, AnimationFlat)
+	if anim.Playing() != AnimationFlat || render.Drawable != spritesheet.Cell(Type1FlatCell) {
+		t.Errorf("playing %q after switching to %q", anim.Playing(), AnimationFlat)
+	}
+
+	// プレイ中でなければ止まる
+	anim.Play(render, AnimationWalk)
+	sim.Run(1, ButtonPause)
+	as.Update(EnermyCellTime * 2)
+	if anim.Cell() != spritesheet.Cell(Type1Cell) {
+		t.Errorf("animation advanced while paused")
+	}
+}
+
+func TestPlayerAnimations(t *testing.T) {
+	sim := newTestSimulation(1, flatLevelFile)
+	sim.Start()
+	player := sim.player.playerEntity
+	sim.Step(1)
+
+	// 立ち止まる、歩く、走る、ジャンプ
+	if got := player.Playing(); got != AnimationIdle {
+		t.Errorf("animation %q at the start, want %q", got, AnimationIdle)
+	}
+	sim.Hold(ButtonMoveRight)
+	sim.Step(2)
+	if got := player.Playing(); got != AnimationWalk {
+		t.Errorf("animation %q when starting to walk, want %q", got, AnimationWalk)
+	}
+	sim.Step(int(RunTime*60) + 1)
+	if got := player.Playing(); got != AnimationRun {
+		t.Errorf("animation %q after walking %vs, want %q", got, RunTime, AnimationRun)
+	}
+	sim.Hold(ButtonMoveRight, ButtonJump)
+	sim.Step(2)
+	if got := player.Playing(); got != AnimationJump {
+		t.Errorf("animation %q in the air, want %q", got, AnimationJump)
+	}
+	sim.Hold()
+}
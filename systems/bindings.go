@@ -0,0 +1,254 @@
+package systems
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/EngoEngine/engo"
+)
+
+// GamepadName : 登録するゲームパッドの名前
+const GamepadName = "Player1"
+
+// StickThreshold : スティックを倒したとみなす値
+const StickThreshold = 0.5
+
+// Binding is the keys and the gamepad buttons of a button
+type Binding struct {
+	// Keys : キーの名前（"D", "ArrowRight", "Space"など）
+	Keys []string `json:"keys"`
+	// Pad : ゲームパッドのボタンの名前（"A", "DpadRight", "StickRight"など）
+	Pad []string `json:"pad"`
+}
+
+// Bindings are the bindings of the buttons, loaded from a bindings file
+type Bindings map[string]Binding
+
+// DefaultBindings returns the bindings used when no bindings file is given
+func DefaultBindings() Bindings {
+	return Bindings{
+		ButtonMoveRight: {Keys: []string{"D", "ArrowRight"}, Pad: []string{"DpadRight", "StickRight"}},
+		ButtonMoveLeft:  {Keys: []string{"A", "ArrowLeft"}, Pad: []string{"DpadLeft", "StickLeft"}},
+		ButtonJump:      {Keys: []string{"Space"}, Pad: []string{"A"}},
+		ButtonEnter:     {Keys: []string{"Enter"}, Pad: []string{"Start"}},
+		ButtonPause:     {Keys: []string{"Escape"}, Pad: []string{"Back"}},
+		ButtonMenuUp:    {Keys: []string{"W", "ArrowUp"}, Pad: []string{"DpadUp", "StickUp"}},
+		ButtonMenuDown:  {Keys: []string{"S", "ArrowDown"}, Pad: []string{"DpadDown", "StickDown"}},
+		ButtonFire:      {Keys: []string{"F", "LeftShift"}, Pad: []string{"B"}},
+	}
+}
+
+// LoadBindings reads a bindings file. The buttons missing in the file keep their default bindings
+func LoadBindings(path string) (Bindings, error) {
+	bindings := DefaultBindings()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return bindings, err
+	}
+	loaded := Bindings{}
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return bindings, err
+	}
+	for name, binding := range loaded {
+		bindings[name] = binding
+	}
+	return bindings, nil
+}
+
+// Register registers the keys of the buttons in engo.Input
+func (b Bindings) Register() {
+	for _, name := range Buttons {
+		keys := make([]engo.Key, 0)
+		for _, keyName := range b[name].Keys {
+			key, ok := keyNames[keyName]
+			if !ok {
+				fmt.Println("Unknown key: " + name + "：" + keyName)
+				continue
+			}
+			keys = append(keys, key)
+		}
+		engo.Input.RegisterButton(name, keys...)
+	}
+}
+
+// GamepadInput is the InputSource reading the buttons of the gamepad registered in engo.Input
+type GamepadInput struct {
+	// Bindings : ボタンの割り当て
+	Bindings Bindings
+}
+
+// Update does nothing, engo updates the gamepads every frame
+func (GamepadInput) Update() {}
+
+// ButtonDown returns whether one of the gamepad buttons of the button is pressed
+func (g GamepadInput) ButtonDown(name string) bool {
+	pad := engo.Input.Gamepad(GamepadName)
+	if pad == nil {
+		return false
+	}
+	for _, padName := range g.Bindings[name].Pad {
+		if padButtonDown(pad, padName) {
+			return true
+		}
+	}
+	return false
+}
+
+// padButtonDown returns whether the button of the gamepad is pressed
+func padButtonDown(pad *engo.Gamepad, name string) bool {
+	var button engo.GamepadButton
+	switch name {
+	case "A":
+		button = pad.A
+	case "B":
+		button = pad.B
+	case "X":
+		button = pad.X
+	case "Y":
+		button = pad.Y
+	case "Back":
+		button = pad.Back
+	case "Start":
+		button = pad.Start
+	case "DpadUp":
+		button = pad.DpadUp
+	case "DpadRight":
+		button = pad.DpadRight
+	case "DpadDown":
+		button = pad.DpadDown
+	case "DpadLeft":
+		button = pad.DpadLeft
+	case "LeftBumper":
+		button = pad.LeftBumper
+	case "RightBumper":
+		button = pad.RightBumper
+	case "StickRight":
+		return pad.LeftX.Value() > StickThreshold
+	case "StickLeft":
+		return pad.LeftX.Value() < -StickThreshold
+	case "StickUp":
+		return pad.LeftY.Value() < -StickThreshold
+	case "StickDown":
+		return pad.LeftY.Value() > StickThreshold
+	default:
+		return false
+	}
+	return button.JustPressed() || button.Down()
+}
+
+// CombinedInput is an InputSource pressing a button when one of its sources presses it
+type CombinedInput []InputSource
+
+// Update updates all the sources
+func (c CombinedInput) Update() {
+	for _, source := range c {
+		source.Update()
+	}
+}
+
+// ButtonDown returns whether one of the sources presses the button
+func (c CombinedInput) ButtonDown(name string) bool {
+	for _, source := range c {
+		if source.ButtonDown(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// keyNames are the names of the keys usable in a bindings file
+var keyNames = map[string]engo.Key{
+	"Grave":        engo.KeyGrave,
+	"Dash":         engo.KeyDash,
+	"Apostrophe":   engo.KeyApostrophe,
+	"Semicolon":    engo.KeySemicolon,
+	"Equals":       engo.KeyEquals,
+	"Comma":        engo.KeyComma,
+	"Period":       engo.KeyPeriod,
+	"Slash":        engo.KeySlash,
+	"Backslash":    engo.KeyBackslash,
+	"Backspace":    engo.KeyBackspace,
+	"Tab":          engo.KeyTab,
+	"CapsLock":     engo.KeyCapsLock,
+	"Space":        engo.KeySpace,
+	"Enter":        engo.KeyEnter,
+	"Escape":       engo.KeyEscape,
+	"Insert":       engo.KeyInsert,
+	"Delete":       engo.KeyDelete,
+	"PageUp":       engo.KeyPageUp,
+	"PageDown":     engo.KeyPageDown,
+	"Home":         engo.KeyHome,
+	"End":          engo.KeyEnd,
+	"ArrowLeft":    engo.KeyArrowLeft,
+	"ArrowRight":   engo.KeyArrowRight,
+	"ArrowDown":    engo.KeyArrowDown,
+	"ArrowUp":      engo.KeyArrowUp,
+	"LeftBracket":  engo.KeyLeftBracket,
+	"RightBracket": engo.KeyRightBracket,
+	"LeftShift":    engo.KeyLeftShift,
+	"LeftControl":  engo.KeyLeftControl,
+	"LeftAlt":      engo.KeyLeftAlt,
+	"RightShift":   engo.KeyRightShift,
+	"RightControl": engo.KeyRightControl,
+	"RightAlt":     engo.KeyRightAlt,
+	"Zero":         engo.KeyZero,
+	"One":          engo.KeyOne,
+	"Two":          engo.KeyTwo,
+	"Three":        engo.KeyThree,
+	"Four":         engo.KeyFour,
+	"Five":         engo.KeyFive,
+	"Six":          engo.KeySix,
+	"Seven":        engo.KeySeven,
+	"Eight":        engo.KeyEight,
+	"Nine":         engo.KeyNine,
+	"F1":           engo.KeyF1,
+	"F2":           engo.KeyF2,
+	"F3":           engo.KeyF3,
+	"F4":           engo.KeyF4,
+	"F5":           engo.KeyF5,
+	"F6":           engo.KeyF6,
+	"F7":           engo.KeyF7,
+	"F8":           engo.KeyF8,
+	"F9":           engo.KeyF9,
+	"F10":          engo.KeyF10,
+	"F11":          engo.KeyF11,
+	"F12":          engo.KeyF12,
+	"A":            engo.KeyA,
+	"B":            engo.KeyB,
+	"C":            engo.KeyC,
+	"D":            engo.KeyD,
+	"E":            engo.KeyE,
+	"F":            engo.KeyF,
+	"G":            engo.KeyG,
+	"H":            engo.KeyH,
+	"I":            engo.KeyI,
+	"J":            engo.KeyJ,
+	"K":            engo.KeyK,
+	"L":            engo.KeyL,
+	"M":            engo.KeyM,
+	"N":            engo.KeyN,
+	"O":            engo.KeyO,
+	"P":            engo.KeyP,
+	"Q":            engo.KeyQ,
+	"R":            engo.KeyR,
+	"S":            engo.KeyS,
+	"T":            engo.KeyT,
+	"U":            engo.KeyU,
+	"V":            engo.KeyV,
+	"W":            engo.KeyW,
+	"X":            engo.KeyX,
+	"Y":            engo.KeyY,
+	"Z":            engo.KeyZ,
+	"NumZero":      engo.KeyNumZero,
+	"NumOne":       engo.KeyNumOne,
+	"NumTwo":       engo.KeyNumTwo,
+	"NumThree":     engo.KeyNumThree,
+	"NumFour":      engo.KeyNumFour,
+	"NumFive":      engo.KeyNumFive,
+	"NumSix":       engo.KeyNumSix,
+	"NumSeven":     engo.KeyNumSeven,
+	"NumEight":     engo.KeyNumEight,
+	"NumNine":      engo.KeyNumNine,
+	"NumEnter":     engo.KeyNumEnter,
+}
@@ -0,0 +1,52 @@
+package systems
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeBindings writes a bindings file into a temporary directory
+func writeBindings(t *testing.T, data string) string {
+	path := filepath.Join(t.TempDir(), "bindings.json")
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadBindingsMissingFile(t *testing.T) {
+	bindings, err := LoadBindings(filepath.Join(t.TempDir(), "missing.json"))
+	if !os.IsNotExist(err) {
+		t.Errorf("error = %v, want a not exist error", err)
+	}
+	if !reflect.DeepEqual(bindings, DefaultBindings()) {
+		t.Errorf("bindings = %v, want the default bindings", bindings)
+	}
+}
+
+func TestLoadBindingsPartialFile(t *testing.T) {
+	path := writeBindings(t, `{"Jump": {"keys": ["Z"], "pad": ["B"]}}`)
+	bindings, err := LoadBindings(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// ファイルにあるボタンだけ置き換える
+	want := DefaultBindings()
+	want[ButtonJump] = Binding{Keys: []string{"Z"}, Pad: []string{"B"}}
+	if !reflect.DeepEqual(bindings, want) {
+		t.Errorf("bindings = %v, want %v", bindings, want)
+	}
+}
+
+func TestLoadBindingsMalformedFile(t *testing.T) {
+	path := writeBindings(t, `{"Jump": {"keys": ["Z"]`)
+	bindings, err := LoadBindings(path)
+	if err == nil || os.IsNotExist(err) {
+		t.Errorf("error = %v, want a syntax error", err)
+	}
+	if !reflect.DeepEqual(bindings, DefaultBindings()) {
+		t.Errorf("bindings = %v, want the default bindings", bindings)
+	}
+}
@@ -0,0 +1,458 @@
+package systems
+
+import (
+	"github.com/EngoEngine/ecs"
+	"github.com/EngoEngine/engo"
+	"github.com/EngoEngine/engo/common"
+)
+
+const (
+	// BlockBrick : レンガブロック
+	BlockBrick = 0
+	// BlockQuestion : ？ブロック
+	BlockQuestion = 1
+)
+
+const (
+	// ItemCoin : コイン
+	ItemCoin = 0
+	// ItemMushroom : スーパーキノコ
+	ItemMushroom = 1
+	// ItemFireFlower : ファイアフラワー
+	ItemFireFlower = 2
+	// ItemStar : スーパースター
+	ItemStar = 3
+)
+
+const (
+	// UsedSpriteSheetCell : スプライトシートで使用する叩いた後の？ブロックのセル番号
+	UsedSpriteSheetCell = 2
+	// BrickSpriteSheetCell : スプライトシートで使用するレンガブロックのセル番号
+	BrickSpriteSheetCell = 3
+	// QuestionSpriteSheetCell : スプライトシートで使用する？ブロックのセル番号
+	QuestionSpriteSheetCell = 4
+	// DebrisSpriteSheetCell : スプライトシートで使用するレンガブロックの破片のセル番号
+	DebrisSpriteSheetCell = 8
+	// CoinSpriteSheetCell : Items.pngで使用するコインのセル番号（4コマ）
+	CoinSpriteSheetCell = 5
+)
+
+const (
+	// BumpTime : 叩いたブロックが跳ねる時間（秒）
+	BumpTime = 0.2
+	// BumpHeight : 叩いたブロックが跳ねる高さ（px）
+	BumpHeight = 6
+	// ItemRiseTime : アイテムがブロックから出てくる時間（秒）
+	ItemRiseTime = 0.5
+	// ItemSpeed : アイテムの移動速度（px/s）
+	ItemSpeed = 60
+	// StarBounceSpeed : スーパースターが跳ねる初速（px/s）
+	StarBounceSpeed = 400
+	// CoinTime : ブロックから出たコインが消えるまでの時間（秒）
+	CoinTime = 0.4
+	// CoinSpeed : ブロックから出たコインの初速（px/s）
+	CoinSpeed = 360
+	// DebrisSpeed : レンガブロックの破片の初速（px/s）
+	DebrisSpeed = 300
+	// CoinScore : コインのスコア
+	CoinScore = 200
+	// BrickScore : レンガブロックを壊した時のスコア
+	BrickScore = 50
+	// ItemScore : パワーアップアイテムのスコア
+	ItemScore = 1000
+)
+
+// itemCells : Items.pngで使用するアイテムのセル番号
+var itemCells = map[int]int{
+	ItemCoin:       CoinSpriteSheetCell,
+	ItemMushroom:   0,
+	ItemFireFlower: 2,
+	ItemStar:       3,
+}
+
+var itemFile = "./Mario/Misc/Items.png"
+
+// BlockTile is a brick or a ? block of the BlockSystem
+type BlockTile struct {
+	Tile
+	Block
+	// ブロックのセルの位置
+	col int
+	row int
+	// 跳ねている残り時間（秒）
+	bumpTime float32
+	// 叩いた後の？ブロックか
+	ifUsed bool
+}
+
+// Item is a coin or a power-up released from a ? block, or a piece of a broken brick
+type Item struct {
+	ecs.BasicEntity
+	common.RenderComponent
+	common.SpaceComponent
+	Collider
+	// アイテムの種類（ItemXxxx, 破片の場合は-1）
+	itemType int
+	// 出てきてからの経過時間（秒）
+	elapsed float32
+	// 横方向の速度（右向きが正）
+	velocityX float32
+	// 縦方向の速度（下向きが正）
+	velocityY float32
+	// ブロックから出てくる途中か
+	ifRising bool
+	// 出てきたブロックのY座標
+	blockPositionY float32
+}
+
+// BlockSystem creates the blocks the player can hit from below, and the items released from them
+type BlockSystem struct {
+	// Game : ゲームの状態
+	Game *GameState
+
+	world       *ecs.World
+	blockEntity []*BlockTile
+	itemEntity  []*Item
+	player      *PlayerSystem
+	// スプライトシート
+	tiles *common.Spritesheet
+	items *common.Spritesheet
+}
+
+// Remove removes an Entity from the System
+func (bs *BlockSystem) Remove(basic ecs.BasicEntity) {
+	for i, e := range bs.blockEntity {
+		if e.BasicEntity.ID() == basic.ID() {
+			bs.blockEntity = append(bs.blockEntity[:i], bs.blockEntity[i+1:]...)
+			break
+		}
+	}
+	for i, e := range bs.itemEntity {
+		if e.BasicEntity.ID() == basic.ID() {
+			bs.itemEntity = append(bs.itemEntity[:i], bs.itemEntity[i+1:]...)
+			break
+		}
+	}
+	// RenderSystem, CollisionSystemから削除
+	for _, system := range bs.world.Systems() {
+		switch sys := system.(type) {
+		case *common.RenderSystem:
+			sys.Remove(basic)
+		case *CollisionSystem:
+			sys.Remove(basic)
+		}
+	}
+}
+
+// Update is ran every frame, with `dt` being the time in seconds since the last frame
+func (bs *BlockSystem) Update(dt float32) {
+	// プレイ中でなければリターン
+	if bs.Game.State() != StatePlaying {
+		return
+	}
+	// 叩いたブロックを跳ねさせる
+	for _, block := range bs.blockEntity {
+		if block.bumpTime <= 0 {
+			continue
+		}
+		block.bumpTime -= dt
+		offset := float32(0)
+		if block.bumpTime > BumpTime/2 {
+			offset = BumpHeight * (BumpTime - block.bumpTime) / (BumpTime / 2)
+		} else if block.bumpTime > 0 {
+			offset = BumpHeight * block.bumpTime / (BumpTime / 2)
+		}
+		block.SpaceComponent.Position.Y = float32(block.row*CellHeight16) - offset
+	}
+	// アイテムの移動
+	removed := make([]ecs.BasicEntity, 0)
+	for _, item := range bs.itemEntity {
+		if !bs.updateItem(item, dt) {
+			removed = append(removed, item.BasicEntity)
+		}
+	}
+	// 取られたコイン、画面の外に出たアイテムを削除
+	for _, basic := range removed {
+		bs.Remove(basic)
+	}
+}
+
+// updateItem moves an item. It returns false when the item has to be removed
+func (bs *BlockSystem) updateItem(item *Item, dt float32) bool {
+	item.elapsed += dt
+	// 落とし穴に落ちたか、画面の左に出たら消える
+	left := bs.player.playerEntity.cameraPositionX - engo.WindowWidth()/2
+	if item.SpaceComponent.Position.Y > engo.WindowHeight() || item.SpaceComponent.Position.X+CellWidth16 < left {
+		return false
+	}
+
+	switch item.itemType {
+	case ItemCoin:
+		// 跳び上がって消える
+		if item.elapsed >= CoinTime {
+			return false
+		}
+		item.RenderComponent.Drawable = bs.items.Cell(CoinSpriteSheetCell + int(item.elapsed/CoinTime*8)%4)
+	case ItemMushroom, ItemFireFlower, ItemStar:
+		// ブロックから出てくる
+		if item.ifRising {
+			if item.elapsed < ItemRiseTime {
+				item.SpaceComponent.Position.Y = item.blockPositionY - CellHeight16*item.elapsed/ItemRiseTime
+				return true
+			}
+			// ブロックの上に乗せて、次のフレームから動かす
+			item.SpaceComponent.Position.Y = item.blockPositionY - CellHeight16
+			item.ifRising = false
+			item.Collider.Terrain = true
+			return true
+		}
+		// ファイアフラワーは落ちない
+		if item.itemType == ItemFireFlower {
+			return true
+		}
+	}
+
+	// 着地はCollisionSystemからの通知で判定する
+	item.SpaceComponent.Position.X += item.velocityX * dt
+	item.velocityY += Gravity * dt
+	if item.velocityY > MaxFallSpeed {
+		item.velocityY = MaxFallSpeed
+	}
+	item.SpaceComponent.Position.Y += item.velocityY * dt
+	return true
+}
+
+// onCollision handles the contacts of the player with the blocks, and of the items, reported by the CollisionSystem
+func (bs *BlockSystem) onCollision(contact Contact) {
+	if bs.Game.State() != StatePlaying {
+		return
+	}
+	// プレイヤーが下からブロックを叩いた
+	if contact.Entity.ID() == bs.player.playerEntity.ID() {
+		if contact.Group == GroupTerrain && contact.Side == SideTop {
+			bs.onHeadContact(contact.Box)
+		}
+		return
+	}
+
+	var item *Item
+	for _, e := range bs.itemEntity {
+		if e.ID() == contact.Entity.ID() {
+			item = e
+			break
+		}
+	}
+	if item == nil {
+		return
+	}
+	switch contact.Group {
+	case GroupPlayer:
+		// アイテムを取る
+		bs.player.PlayerGetItem(item.itemType)
+		bs.Remove(item.BasicEntity)
+	case GroupTerrain:
+		switch contact.Side {
+		case SideLeft:
+			// 土管や壁に当たったら向きを変える
+			if item.velocityX < 0 {
+				item.velocityX = -item.velocityX
+			}
+		case SideRight:
+			if item.velocityX > 0 {
+				item.velocityX = -item.velocityX
+			}
+		case SideBottom:
+			// 着地（スーパースターは跳ねる）
+			item.velocityY = 0
+			if item.itemType == ItemStar {
+				item.velocityY = -StarBounceSpeed
+			}
+		case SideTop:
+			if item.velocityY < 0 {
+				item.velocityY = 0
+			}
+		}
+	}
+}
+
+// onHeadContact hits the block above the head of the player.
+// When the head touches two blocks, the block above the center of the player is hit
+func (bs *BlockSystem) onHeadContact(box engo.AABB) {
+	row := int(box.Min.Y) / CellHeight16
+	col := int(bs.player.playerEntity.SpaceComponent.Position.X+CellWidth32/2) / CellWidth16
+	block := bs.blockAt(col, row)
+	if block == nil {
+		block = bs.blockAt(int(box.Min.X)/CellWidth16, row)
+	}
+	if block == nil || block.ifUsed {
+		return
+	}
+
+	switch block.Type {
+	case BlockQuestion:
+		// アイテムを出して叩いた後のブロックになる
+		block.ifUsed = true
+		block.bumpTime = BumpTime
+		block.RenderComponent.Drawable = bs.tiles.Cell(UsedSpriteSheetCell)
+		bs.Game.CollisionMap.Set(block.col, block.row, CellUsed)
+		bs.releaseItem(block)
+	case BlockBrick:
+		// 大きいマリオはレンガブロックを壊す
+		if bs.player.IsBig() {
+			bs.Game.CollisionMap.Set(block.col, block.row, CellEmpty)
+			bs.Game.Progress.AddScore(BrickScore)
+			bs.breakBrick(block)
+			bs.Remove(block.BasicEntity)
+		} else {
+			block.bumpTime = BumpTime
+		}
+	}
+}
+
+// blockAt returns the block at col, row, or nil if there is no block
+func (bs *BlockSystem) blockAt(col, row int) *BlockTile {
+	for _, block := range bs.blockEntity {
+		if block.col == col && block.row == row {
+			return block
+		}
+	}
+	return nil
+}
+
+// releaseItem makes the item of a ? block come out of it
+func (bs *BlockSystem) releaseItem(block *BlockTile) {
+	item := &Item{BasicEntity: ecs.NewBasic()}
+	item.itemType = block.Item
+	item.SpaceComponent = common.SpaceComponent{
+		Position: engo.Point{X: float32(block.col * CellWidth16), Y: float32(block.row * CellHeight16)},
+		Width:    CellWidth16,
+		Height:   CellHeight16,
+	}
+	item.RenderComponent = common.RenderComponent{
+		Drawable: bs.items.Cell(itemCells[block.Item]),
+		Scale:    engo.Point{X: 1, Y: 1},
+	}
+	// ブロックの後ろから出てくる
+	item.RenderComponent.SetZIndex(3)
+
+	switch block.Item {
+	case ItemCoin:
+		// コインはすぐに取れる
+		bs.Game.Progress.AddCoin()
+		bs.Game.Progress.AddScore(CoinScore)
+		item.velocityY = -CoinSpeed
+		bs.addItem(item, false)
+	default:
+		item.ifRising = true
+		item.blockPositionY = item.SpaceComponent.Position.Y
+		// ファイアフラワーは動かない
+		if block.Item != ItemFireFlower {
+			item.velocityX = ItemSpeed
+		}
+		item.Collider = Collider{Group: GroupItem}
+		bs.addItem(item, true)
+	}
+}
+
+// breakBrick scatters the pieces of a broken brick
+func (bs *BlockSystem) breakBrick(block *BlockTile) {
+	for i := 0; i < 4; i++ {
+		piece := &Item{BasicEntity: ecs.NewBasic()}
+		piece.itemType = -1
+		// 左右上下に1つずつ
+		dx := float32(i%2) * CellWidth16 / 2
+		dy := float32(i/2) * CellHeight16 / 2
+		piece.SpaceComponent = common.SpaceComponent{
+			Position: engo.Point{X: float32(block.col*CellWidth16) + dx, Y: float32(block.row*CellHeight16) + dy},
+			Width:    CellWidth16,
+			Height:   CellHeight16,
+		}
+		piece.RenderComponent = common.RenderComponent{
+			Drawable: bs.tiles.Cell(DebrisSpriteSheetCell + i%2),
+			Scale:    engo.Point{X: 1, Y: 1},
+		}
+		piece.RenderComponent.SetZIndex(8)
+		piece.velocityX = DebrisSpeed / 4 * float32(i%2*2-1)
+		piece.velocityY = -DebrisSpeed + float32(i/2)*DebrisSpeed/3
+		bs.addItem(piece, false)
+	}
+}
+
+// addItem adds an item to the BlockSystem, the RenderSystem and, if it can be taken, the CollisionSystem
+func (bs *BlockSystem) addItem(item *Item, collision bool) {
+	bs.itemEntity = append(bs.itemEntity, item)
+	for _, system := range bs.world.Systems() {
+		switch sys := system.(type) {
+		case *common.RenderSystem:
+			sys.Add(&item.BasicEntity, &item.RenderComponent, &item.SpaceComponent)
+		case *CollisionSystem:
+			if collision {
+				sys.Add(&item.BasicEntity, &item.SpaceComponent, &item.Collider)
+			}
+		}
+	}
+}
+
+// New is the initialisation of the System
+func (bs *BlockSystem) New(w *ecs.World) {
+	//　Worldの追加
+	bs.world = w
+	// プレイヤーの取得
+	for _, system := range bs.world.Systems() {
+		switch sys := system.(type) {
+		case *PlayerSystem:
+			bs.player = sys
+		}
+	}
+	// 接触の通知
+	engo.Mailbox.Listen("CollisionMessage", func(msg engo.Message) {
+		contact, ok := msg.(CollisionMessage)
+		if !ok {
+			return
+		}
+		bs.onCollision(contact.Contact)
+	})
+
+	// スプライトシートの作成
+	bs.tiles = common.NewSpritesheetWithBorderFromFile(tileFile, CellWidth16, CellHeight16, 0, 0)
+	bs.items = common.NewSpritesheetWithBorderFromFile(itemFile, CellWidth16, CellHeight16, 0, 0)
+
+	// BlockTile配列作成
+	Blocks := make([]*BlockTile, 0)
+	for _, v := range bs.Game.Level.Blocks {
+		block := &BlockTile{Tile: Tile{BasicEntity: ecs.NewBasic()}, Block: v}
+		block.col = v.X
+		block.row = int(blockPositionY(v.Height)) / CellHeight16
+
+		// SpaceComponent
+		block.SpaceComponent = common.SpaceComponent{
+			Position: engo.Point{X: float32(block.col * CellWidth16), Y: float32(block.row * CellHeight16)},
+			Width:    CellWidth16,
+			Height:   CellHeight16,
+		}
+
+		// RenderComponent
+		cell := BrickSpriteSheetCell
+		if v.Type == BlockQuestion {
+			cell = QuestionSpriteSheetCell
+		}
+		block.RenderComponent = common.RenderComponent{
+			Drawable: bs.tiles.Cell(cell),
+			Scale:    engo.Point{X: 1, Y: 1},
+		}
+		block.RenderComponent.SetZIndex(4)
+
+		// コンポーネントセット
+		Blocks = append(Blocks, block)
+	}
+	bs.blockEntity = Blocks
+	// RenderSystemに追加
+	for _, system := range bs.world.Systems() {
+		switch sys := system.(type) {
+		case *common.RenderSystem:
+			for _, v := range Blocks {
+				sys.Add(&v.BasicEntity, &v.RenderComponent, &v.SpaceComponent)
+			}
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package systems
+
+import (
+	"image/color"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/EngoEngine/engo"
+	"github.com/EngoEngine/engo/common"
+)
+
+const (
+	// CheckpointPoleWidth : 中間地点のポールの幅（px）
+	CheckpointPoleWidth = 2
+	// CheckpointPoleHeight : 中間地点のポールの高さ（px）
+	CheckpointPoleHeight = 48
+	// CheckpointFlagWidth : 中間地点の旗の幅（px）
+	CheckpointFlagWidth = 12
+	// CheckpointFlagHeight : 中間地点の旗の高さ（px）
+	CheckpointFlagHeight = 8
+)
+
+var (
+	// poleColor : ポールの色
+	poleColor = color.RGBA{200, 200, 200, 255}
+	// checkpointColor : 通過する前の旗の色
+	checkpointColor = color.RGBA{255, 255, 255, 255}
+	// checkpointPassedColor : 通過した後の旗の色
+	checkpointPassedColor = color.RGBA{230, 40, 40, 255}
+)
+
+// Checkpoint is a checkpoint marker, a pole with a flag
+type Checkpoint struct {
+	// タイル位置
+	x    int
+	pole *Tile
+	flag *Tile
+}
+
+// CheckpointSystem places the checkpoints of the course and records the last one the player touched,
+// where the player respawns after a death
+type CheckpointSystem struct {
+	// Game : ゲームの状態
+	Game *GameState
+
+	world       *ecs.World
+	checkpoints []*Checkpoint
+	player      *PlayerSystem
+}
+
+// Remove removes an Entity from the System
+func (*CheckpointSystem) Remove(ecs.BasicEntity) {}
+
+// Update is ran every frame, with `dt` being the time in seconds since the last frame
+func (cs *CheckpointSystem) Update(dt float32) {
+	// プレイ中でなければリターン
+	if cs.Game.State() != StatePlaying {
+		return
+	}
+	// プレイヤーがポールに触れたら中間地点を記録する
+	player := cs.player.bounds(0, 0)
+	for _, checkpoint := range cs.checkpoints {
+		if checkpoint.x <= cs.Game.Checkpoint() {
+			continue
+		}
+		if overlaps(player, checkpoint.pole.SpaceComponent.AABB()) {
+			cs.Game.SetCheckpoint(checkpoint.x)
+			checkpoint.flag.RenderComponent.Color = checkpointPassedColor
+		}
+	}
+}
+
+// New is the initialisation of the System
+func (cs *CheckpointSystem) New(w *ecs.World) {
+	//　Worldの追加
+	cs.world = w
+	// プレイヤーの取得
+	for _, system := range cs.world.Systems() {
+		switch sys := system.(type) {
+		case *PlayerSystem:
+			cs.player = sys
+		}
+	}
+
+	for _, x := range cs.Game.Level.Checkpoints {
+		// ポールはタイルの中央に立てる
+		poleX := float32(x*CellWidth16) + (CellWidth16-CheckpointPoleWidth)/2
+		poleY := groundPositionY() + CellHeight32 - CheckpointPoleHeight
+		pole := &Tile{BasicEntity: ecs.NewBasic()}
+		pole.SpaceComponent = common.SpaceComponent{
+			Position: engo.Point{X: poleX, Y: poleY},
+			Width:    CheckpointPoleWidth,
+			Height:   CheckpointPoleHeight,
+		}
+		pole.RenderComponent = common.RenderComponent{
+			Drawable: common.Rectangle{},
+			Color:    poleColor,
+		}
+		pole.RenderComponent.SetZIndex(3)
+
+		// 旗はポールの上端に付ける（通過済みの中間地点からやり直した場合は通過後の色）
+		flag := &Tile{BasicEntity: ecs.NewBasic()}
+		flag.SpaceComponent = common.SpaceComponent{
+			Position: engo.Point{X: poleX + CheckpointPoleWidth, Y: poleY},
+			Width:    CheckpointFlagWidth,
+			Height:   CheckpointFlagHeight,
+		}
+		flag.RenderComponent = common.RenderComponent{
+			Drawable: common.Rectangle{},
+			Color:    checkpointColor,
+		}
+		if x <= cs.Game.Checkpoint() {
+			flag.RenderComponent.Color = checkpointPassedColor
+		}
+		flag.RenderComponent.SetZIndex(3)
+
+		cs.checkpoints = append(cs.checkpoints, &Checkpoint{x: x, pole: pole, flag: flag})
+	}
+	// RenderSystemに追加
+	for _, system := range cs.world.Systems() {
+		switch sys := system.(type) {
+		case *common.RenderSystem:
+			for _, v := range cs.checkpoints {
+				sys.Add(&v.pole.BasicEntity, &v.pole.RenderComponent, &v.pole.SpaceComponent)
+				sys.Add(&v.flag.BasicEntity, &v.flag.RenderComponent, &v.flag.SpaceComponent)
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package systems
+
+import (
+	"testing"
+)
+
+// testCheckpoints returns the CheckpointSystem of the simulation
+func testCheckpoints(sim *Simulation) *CheckpointSystem {
+	for _, system := range sim.world.Systems() {
+		switch sys := system.(type) {
+		case *CheckpointSystem:
+			return sys
+		}
+	}
+	return nil
+}
+
+// movePlayer puts the player at the position (top left of its sprite)
+func movePlayer(sim *Simulation, x, y float32) {
+	player := sim.player.playerEntity
+	player.SpaceComponent.Position.X = x
+	player.SpaceComponent.Position.Y = y
+	player.LeftPositionX = x + ExtraSizeX
+	player.RightPositionX = x + CellWidth32 - ExtraSizeX
+}
+
+func TestCheckpointTouch(t *testing.T) {
+	tests := []struct {
+		name string
+		// ポールからのプレイヤーの位置
+		dx, dy float32
+		state  int
+		want   bool
+	}{
+		{"touching the pole", -CellWidth16, 0, StatePlaying, true},
+		{"before the pole", -CellWidth32, 0, StatePlaying, false},
+		{"past the pole", CellWidth16, 0, StatePlaying, false},
+		{"above the pole", -CellWidth16, -CheckpointPoleHeight - CellHeight32, StatePlaying, false},
+		{"dying at the pole", -CellWidth16, 0, StateDying, false},
+	}
+	for _, tt := range tests {
+		sim := newTestSimulation(1, flatLevelFile)
+		sim.Start()
+		cs := testCheckpoints(sim)
+		if len(cs.checkpoints) != 1 {
+			t.Fatalf("%d checkpoints, want 1", len(cs.checkpoints))
+		}
+		checkpoint := cs.checkpoints[0]
+
+		movePlayer(sim, checkpoint.pole.SpaceComponent.Position.X+tt.dx, groundPositionY()+tt.dy)
+		sim.game.SetState(tt.state)
+		cs.Update(StepTime)
+		if got := sim.game.Checkpoint() == checkpoint.x; got != tt.want {
+			t.Errorf("%s: checkpoint recorded %v, want %v", tt.name, got, tt.want)
+		}
+		if got := checkpoint.flag.RenderComponent.Color == checkpointPassedColor; got != tt.want {
+			t.Errorf("%s: flag passed %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCheckpointRespawn(t *testing.T) {
+	sim := newTestSimulation(1, flatLevelFile)
+	sim.Start()
+
+	// 歩いてポールに触れる
+	runUntil(sim, 300, func() bool { return sim.game.Checkpoint() != 0 }, ButtonMoveRight)
+	if sim.game.Checkpoint() != 12 {
+		t.Fatalf("checkpoint %d after walking, want 12", sim.game.Checkpoint())
+	}
+	// 死亡後は中間地点からやり直す
+	sim.player.PlayerDie()
+	runUntil(sim, 600, func() bool { return sim.State() == StatePlaying })
+	if sim.State() != StatePlaying {
+		t.Fatalf("state %d after the death, want StatePlaying", sim.State())
+	}
+	if x := sim.PlayerPosition().X; x != 12*CellWidth16 {
+		t.Errorf("respawned at %v, want %v", x, 12*CellWidth16)
+	}
+}
@@ -0,0 +1,74 @@
+package systems
+
+import "github.com/EngoEngine/engo"
+
+const (
+	// CellEmpty : 何もないセル
+	CellEmpty = 0
+	// CellGround : 地面のセル
+	CellGround = 1
+	// CellPipe : 土管のセル
+	CellPipe = 2
+	// CellBrick : レンガブロックのセル
+	CellBrick = 3
+	// CellQuestion : ？ブロックのセル
+	CellQuestion = 4
+	// CellUsed : 叩いた後の？ブロックのセル
+	CellUsed = 5
+)
+
+// CollisionMap is a tile-indexed map of the solid cells of a course.
+// Each cell is CellWidth16 x CellHeight16 and the row 0 is the top of the screen.
+type CollisionMap struct {
+	cols  int
+	rows  int
+	cells []int
+}
+
+// NewCollisionMap creates an empty CollisionMap
+func NewCollisionMap(cols, rows int) *CollisionMap {
+	return &CollisionMap{
+		cols:  cols,
+		rows:  rows,
+		cells: make([]int, cols*rows),
+	}
+}
+
+// Set sets the type of the cell at col, row
+func (m *CollisionMap) Set(col, row, cell int) {
+	if col < 0 || col >= m.cols || row < 0 || row >= m.rows {
+		return
+	}
+	m.cells[row*m.cols+col] = cell
+}
+
+// Cell returns the type of the cell at col, row. Cells outside of the map are empty
+func (m *CollisionMap) Cell(col, row int) int {
+	if col < 0 || col >= m.cols || row < 0 || row >= m.rows {
+		return CellEmpty
+	}
+	return m.cells[row*m.cols+col]
+}
+
+// At returns the type of the cell containing the world position x, y
+func (m *CollisionMap) At(x, y float32) int {
+	if x < 0 || y < 0 {
+		return CellEmpty
+	}
+	return m.Cell(int(x)/CellWidth16, int(y)/CellHeight16)
+}
+
+// Solid returns the first solid cell overlapping the bounds and the bounds of the cell
+func (m *CollisionMap) Solid(bounds engo.AABB) (int, engo.AABB) {
+	for x := int(bounds.Min.X) / CellWidth16; float32(x*CellWidth16) < bounds.Max.X; x++ {
+		for y := int(bounds.Min.Y) / CellHeight16; float32(y*CellHeight16) < bounds.Max.Y; y++ {
+			if cell := m.Cell(x, y); cell != CellEmpty {
+				return cell, engo.AABB{
+					Min: engo.Point{X: float32(x * CellWidth16), Y: float32(y * CellHeight16)},
+					Max: engo.Point{X: float32((x + 1) * CellWidth16), Y: float32((y + 1) * CellHeight16)},
+				}
+			}
+		}
+	}
+	return CellEmpty, engo.AABB{}
+}
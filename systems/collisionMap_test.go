@@ -0,0 +1,109 @@
+package systems
+
+import (
+	"testing"
+
+	"github.com/EngoEngine/engo"
+)
+
+func TestCollisionMapOutOfRange(t *testing.T) {
+	m := NewCollisionMap(4, 3)
+	// 範囲外のSetは無視される
+	for _, c := range [][2]int{{-1, 0}, {0, -1}, {4, 0}, {0, 3}, {100, 100}} {
+		m.Set(c[0], c[1], CellGround)
+	}
+	m.Set(3, 2, CellPipe)
+
+	tests := []struct {
+		name     string
+		col, row int
+		want     int
+	}{
+		{"negative col", -1, 0, CellEmpty},
+		{"negative row", 0, -1, CellEmpty},
+		{"past width", 4, 0, CellEmpty},
+		{"past height", 0, 3, CellEmpty},
+		{"far outside", 100, 100, CellEmpty},
+		{"first cell", 0, 0, CellEmpty},
+		{"last cell", 3, 2, CellPipe},
+	}
+	for _, tt := range tests {
+		if got := m.Cell(tt.col, tt.row); got != tt.want {
+			t.Errorf("%s: Cell(%d, %d) = %d, want %d", tt.name, tt.col, tt.row, got, tt.want)
+		}
+	}
+
+	atTests := []struct {
+		name string
+		x, y float32
+		want int
+	}{
+		{"negative x", -1, 40, CellEmpty},
+		{"negative y", 56, -1, CellEmpty},
+		{"past width", 4 * CellWidth16, 40, CellEmpty},
+		{"past height", 56, 3 * CellHeight16, CellEmpty},
+		{"inside last cell", 3*CellWidth16 + 8, 2*CellHeight16 + 8, CellPipe},
+	}
+	for _, tt := range atTests {
+		if got := m.At(tt.x, tt.y); got != tt.want {
+			t.Errorf("%s: At(%v, %v) = %d, want %d", tt.name, tt.x, tt.y, got, tt.want)
+		}
+	}
+
+	// 範囲外にはみ出した矩形は範囲内のセルだけを見る
+	cell, _ := m.Solid(engo.AABB{Min: engo.Point{X: -32, Y: -32}, Max: engo.Point{X: 8, Y: 8}})
+	if cell != CellEmpty {
+		t.Errorf("Solid outside of the map = %d, want %d", cell, CellEmpty)
+	}
+	cell, bounds := m.Solid(engo.AABB{Min: engo.Point{X: 40, Y: 24}, Max: engo.Point{X: 200, Y: 200}})
+	want := engo.AABB{Min: engo.Point{X: 48, Y: 32}, Max: engo.Point{X: 64, Y: 48}}
+	if cell != CellPipe || bounds != want {
+		t.Errorf("Solid = %d %v, want %d %v", cell, bounds, CellPipe, want)
+	}
+}
+
+// headlessScene is an empty scene, to run engo without a window
+type headlessScene struct{}
+
+func (*headlessScene) Type() string { return "HeadlessTest" }
+
+func (*headlessScene) Preload() {}
+
+func (*headlessScene) Setup(engo.Updater) {}
+
+func TestBuildCollisionMap(t *testing.T) {
+	// 画面サイズの設定
+	engo.Run(engo.RunOptions{HeadlessMode: true, NoRun: true, Width: 480, Height: 320}, &headlessScene{})
+
+	ts := &TileSystem{Game: &GameState{Level: &Level{Width: 20, Pits: []int{5, 6}, Pipes: []int{10}}}}
+	m := ts.buildCollisionMap()
+
+	// 地面は下からTileDepth行、土管は2x2タイル
+	bottom := int(engo.WindowHeight())/CellHeight16 - 1
+	top := bottom - TileDepth + 1
+	pipeRow := int(pipePositionY()) / CellHeight16
+	tests := []struct {
+		name     string
+		col, row int
+		want     int
+	}{
+		{"ground bottom", 0, bottom, CellGround},
+		{"ground top", 4, top, CellGround},
+		{"above ground", 4, top - 1, CellEmpty},
+		{"pit bottom", 5, bottom, CellEmpty},
+		{"pit top", 6, top, CellEmpty},
+		{"after pit", 7, top, CellGround},
+		{"pipe top left", 10, pipeRow, CellPipe},
+		{"pipe bottom right", 11, pipeRow + 1, CellPipe},
+		{"above pipe", 10, pipeRow - 1, CellEmpty},
+		{"right of pipe", 12, pipeRow, CellEmpty},
+		{"ground under pipe", 10, top, CellGround},
+		{"last column", 20, bottom, CellGround},
+		{"past last column", 21, bottom, CellEmpty},
+	}
+	for _, tt := range tests {
+		if got := m.Cell(tt.col, tt.row); got != tt.want {
+			t.Errorf("%s: Cell(%d, %d) = %d, want %d", tt.name, tt.col, tt.row, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package systems
+
+import (
+	"github.com/EngoEngine/ecs"
+	"github.com/EngoEngine/engo"
+	"github.com/EngoEngine/engo/common"
+)
+
+const (
+	// SideNone : 接触なし
+	SideNone = 0
+	// SideTop : 上側
+	SideTop = 1
+	// SideBottom : 下側
+	SideBottom = 2
+	// SideLeft : 左側
+	SideLeft = 3
+	// SideRight : 右側
+	SideRight = 4
+)
+
+// CollisionSystemPriority : CollisionSystemの優先度
+const CollisionSystemPriority = -10
+
+const (
+	// GroupTerrain : 地形
+	GroupTerrain = 0
+	// GroupPlayer : プレイヤー
+	GroupPlayer = 1
+	// GroupEnemy : 敵キャラ
+	GroupEnemy = 2
+	// GroupItem : アイテム
+	GroupItem = 3
+	// GroupFireball : ファイアボール
+	GroupFireball = 4
+)
+
+// Collider is the component of the entities checked by the CollisionSystem
+type Collider struct {
+	// Group : 種類（GroupPlayer, GroupEnemy, GroupItem, GroupFireball）
+	Group int
+	// Inset : 画像の余白（X：左右, Y：上）
+	Inset engo.Point
+	// Terrain : 地形と衝突するか
+	Terrain bool
+	// SameGroup : 同じ種類のEntityとも接触するか（蹴られた甲羅）
+	SameGroup bool
+}
+
+// Contact is a contact of an entity with the terrain or with another entity
+type Contact struct {
+	// Entity : 接触したEntity
+	Entity *ecs.BasicEntity
+	// Other : 接触した相手のEntity（地形の場合はnil）
+	Other *ecs.BasicEntity
+	// Group : 接触した相手の種類（地形の場合はGroupTerrain）
+	Group int
+	// Cell : 接触した地形のセル（Entityの場合はCellEmpty）
+	Cell int
+	// Box : 接触した地形のセルの範囲
+	Box engo.AABB
+	// Side : Entityの接触した側
+	Side int
+	// Last : 接触する前（前フレーム）のEntityの範囲（Entity同士の場合）
+	Last engo.AABB
+	// OtherLast : 接触する前（前フレーム）の相手のEntityの範囲
+	OtherLast engo.AABB
+}
+
+// CollisionMessage is dispatched by the CollisionSystem for every contact
+type CollisionMessage struct {
+	Contact
+}
+
+// Type implements the engo.Message interface
+func (CollisionMessage) Type() string { return "CollisionMessage" }
+
+// collisionEntity is an entity of the CollisionSystem
+type collisionEntity struct {
+	*ecs.BasicEntity
+	*common.SpaceComponent
+	*Collider
+	// 前フレームの位置
+	lastPosition engo.Point
+}
+
+// bounds returns the hitbox of the entity at the position
+func (e *collisionEntity) bounds(position engo.Point) engo.AABB {
+	return engo.AABB{
+		Min: engo.Point{X: position.X + e.Inset.X, Y: position.Y + e.Inset.Y},
+		Max: engo.Point{X: position.X + e.Width - e.Inset.X, Y: position.Y + e.Height},
+	}
+}
+
+// CollisionSystem resolves the contacts of the player and the enemies with the terrain and with each other
+type CollisionSystem struct {
+	// Game : ゲームの状態
+	Game *GameState
+
+	world    *ecs.World
+	entities []*collisionEntity
+	// 通知待ちの接触
+	contacts []Contact
+}
+
+// Priority runs the CollisionSystem after the systems moving the entities, before the RenderSystem
+func (*CollisionSystem) Priority() int { return CollisionSystemPriority }
+
+// Add adds an entity to the CollisionSystem
+func (cs *CollisionSystem) Add(basic *ecs.BasicEntity, space *common.SpaceComponent, collider *Collider) {
+	cs.entities = append(cs.entities, &collisionEntity{
+		BasicEntity:    basic,
+		SpaceComponent: space,
+		Collider:       collider,
+		lastPosition:   space.Position,
+	})
+}
+
+// Remove removes an Entity from the System
+func (cs *CollisionSystem) Remove(basic ecs.BasicEntity) {
+	index := -1
+	for i, e := range cs.entities {
+		if e.BasicEntity.ID() == basic.ID() {
+			index = i
+			break
+		}
+	}
+	if index >= 0 {
+		cs.entities = append(cs.entities[:index], cs.entities[index+1:]...)
+	}
+}
+
+// Update is ran every frame, with `dt` being the time in seconds since the last frame
+func (cs *CollisionSystem) Update(dt float32) {
+	// プレイ中でなければ判定せず位置だけ記録する
+	if cs.Game.State() != StatePlaying {
+		for _, e := range cs.entities {
+			e.lastPosition = e.Position
+		}
+		return
+	}
+	// 地形との接触
+	for _, e := range cs.entities {
+		if e.Terrain {
+			cs.resolveTerrain(e)
+		}
+	}
+	// Entity同士の接触
+	for i, e := range cs.entities {
+		for _, other := range cs.entities[i+1:] {
+			// 同じ種類同士は接触しない（蹴られた甲羅は除く）
+			if e.Group == other.Group && !e.SameGroup && !other.SameGroup {
+				continue
+			}
+			if !overlaps(e.bounds(e.Position), other.bounds(other.Position)) {
+				continue
+			}
+			last, otherLast := e.bounds(e.lastPosition), other.bounds(other.lastPosition)
+			side := contactSide(last, otherLast)
+			cs.contacts = append(cs.contacts,
+				Contact{Entity: e.BasicEntity, Other: other.BasicEntity, Group: other.Group, Side: side, Last: last, OtherLast: otherLast},
+				Contact{Entity: other.BasicEntity, Other: e.BasicEntity, Group: e.Group, Side: oppositeSide(side), Last: otherLast, OtherLast: last})
+		}
+	}
+	// 位置の記録
+	for _, e := range cs.entities {
+		e.lastPosition = e.Position
+	}
+	// 接触の通知（通知先でEntityが削除されることがあるため最後に行う）
+	contacts := cs.contacts
+	cs.contacts = nil
+	for _, contact := range contacts {
+		engo.Mailbox.Dispatch(CollisionMessage{contact})
+	}
+}
+
+// resolveTerrain pushes the entity out of the solid cells, first horizontally then vertically
+func (cs *CollisionSystem) resolveTerrain(e *collisionEntity) {
+	position := e.lastPosition
+
+	// 横方向
+	position.X = e.Position.X
+	if cell, box := cs.Game.CollisionMap.Solid(e.bounds(position)); cell != CellEmpty && position.X != e.lastPosition.X {
+		side := SideRight
+		if e.Position.X < e.lastPosition.X {
+			side = SideLeft
+			position.X = box.Max.X - e.Inset.X
+		} else {
+			position.X = box.Min.X - e.Width + e.Inset.X
+		}
+		cs.contacts = append(cs.contacts, Contact{Entity: e.BasicEntity, Cell: cell, Box: box, Side: side})
+	}
+
+	// 縦方向
+	position.Y = e.Position.Y
+	if cell, box := cs.Game.CollisionMap.Solid(e.bounds(position)); cell != CellEmpty && position.Y != e.lastPosition.Y {
+		side := SideBottom
+		if e.Position.Y < e.lastPosition.Y {
+			side = SideTop
+			position.Y = box.Max.Y - e.Inset.Y
+		} else {
+			position.Y = box.Min.Y - e.Height
+		}
+		cs.contacts = append(cs.contacts, Contact{Entity: e.BasicEntity, Cell: cell, Box: box, Side: side})
+	}
+
+	e.Position = position
+}
+
+// New is the initialisation of the System
+func (cs *CollisionSystem) New(w *ecs.World) {
+	//　Worldの追加
+	cs.world = w
+}
+
+// overlaps returns whether the two bounds overlap
+func overlaps(a, b engo.AABB) bool {
+	return a.Min.X < b.Max.X && a.Max.X > b.Min.X && a.Min.Y < b.Max.Y && a.Max.Y > b.Min.Y
+}
+
+// contactSide returns the side of a touched by b, from their bounds before the contact
+func contactSide(a, b engo.AABB) int {
+	switch {
+	case a.Max.Y <= b.Min.Y:
+		return SideBottom
+	case a.Min.Y >= b.Max.Y:
+		return SideTop
+	case a.Max.X <= b.Min.X:
+		return SideRight
+	case a.Min.X >= b.Max.X:
+		return SideLeft
+	}
+	// 前フレームから重なっている場合
+	return SideNone
+}
+
+// oppositeSide returns the side facing the side
+func oppositeSide(side int) int {
+	switch side {
+	case SideTop:
+		return SideBottom
+	case SideBottom:
+		return SideTop
+	case SideLeft:
+		return SideRight
+	case SideRight:
+		return SideLeft
+	}
+	return SideNone
+}
@@ -0,0 +1,76 @@
+package systems
+
+import (
+	"testing"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/EngoEngine/engo"
+	"github.com/EngoEngine/engo/common"
+)
+
+func TestContactSide(t *testing.T) {
+	// b : 16x16の相手の前フレームの範囲
+	b := engo.AABB{Min: engo.Point{X: 32, Y: 32}, Max: engo.Point{X: 48, Y: 48}}
+	tests := []struct {
+		name string
+		// a : 前フレームの範囲の左上（16x16）
+		x, y float32
+		want int
+	}{
+		{"above", 32, 16, SideBottom},
+		{"below", 32, 48, SideTop},
+		{"left", 16, 32, SideRight},
+		{"right", 48, 32, SideLeft},
+		{"above and left", 20, 16, SideBottom},
+		{"already overlapping", 36, 36, SideNone},
+	}
+	for _, tt := range tests {
+		a := engo.AABB{Min: engo.Point{X: tt.x, Y: tt.y}, Max: engo.Point{X: tt.x + 16, Y: tt.y + 16}}
+		if got := contactSide(a, b); got != tt.want {
+			t.Errorf("%s: side %d, want %d", tt.name, got, tt.want)
+		}
+		if got := contactSide(b, a); got != oppositeSide(tt.want) {
+			t.Errorf("%s: opposite side %d, want %d", tt.name, got, oppositeSide(tt.want))
+		}
+	}
+}
+
+func TestResolveTerrain(t *testing.T) {
+	// 一番下の行が地面、(4, 2)に壁、(1, 0)に天井
+	m := NewCollisionMap(8, 4)
+	for i := 0; i < 8; i++ {
+		m.Set(i, 3, CellGround)
+	}
+	m.Set(4, 2, CellGround)
+	m.Set(1, 0, CellGround)
+
+	tests := []struct {
+		name     string
+		from, to engo.Point
+		want     engo.Point
+		side     int
+	}{
+		{"walk into the wall", engo.Point{X: 44, Y: 32}, engo.Point{X: 50, Y: 32}, engo.Point{X: 48, Y: 32}, SideRight},
+		{"walk into the wall from the right", engo.Point{X: 84, Y: 32}, engo.Point{X: 78, Y: 32}, engo.Point{X: 80, Y: 32}, SideLeft},
+		{"land on the ground", engo.Point{X: 16, Y: 28}, engo.Point{X: 16, Y: 36}, engo.Point{X: 16, Y: 32}, SideBottom},
+		{"jump into the ceiling", engo.Point{X: 16, Y: 20}, engo.Point{X: 16, Y: 12}, engo.Point{X: 16, Y: 16}, SideTop},
+		{"land on the wall while walking", engo.Point{X: 66, Y: 12}, engo.Point{X: 68, Y: 20}, engo.Point{X: 68, Y: 16}, SideBottom},
+		{"walk freely", engo.Point{X: 16, Y: 32}, engo.Point{X: 20, Y: 32}, engo.Point{X: 20, Y: 32}, SideNone},
+	}
+	for _, tt := range tests {
+		cs := &CollisionSystem{Game: &GameState{CollisionMap: m}}
+		space := &common.SpaceComponent{Position: tt.to, Width: 16, Height: 16}
+		e := &collisionEntity{BasicEntity: &ecs.BasicEntity{}, SpaceComponent: space, Collider: &Collider{Terrain: true}, lastPosition: tt.from}
+		cs.resolveTerrain(e)
+		if e.Position != tt.want {
+			t.Errorf("%s: position %v, want %v", tt.name, e.Position, tt.want)
+		}
+		side := SideNone
+		if len(cs.contacts) > 0 {
+			side = cs.contacts[len(cs.contacts)-1].Side
+		}
+		if side != tt.side {
+			t.Errorf("%s: side %d, want %d", tt.name, side, tt.side)
+		}
+	}
+}
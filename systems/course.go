@@ -0,0 +1,46 @@
+package systems
+
+import (
+	"image/color"
+)
+
+const (
+	// ThemeOverworld : 地上
+	ThemeOverworld = 0
+	// ThemeUnderground : 地下
+	ThemeUnderground = 1
+	// ThemeCastle : 城
+	ThemeCastle = 2
+)
+
+// themeBackgrounds : テーマ毎の背景色
+var themeBackgrounds = map[int]color.Color{
+	ThemeOverworld:   color.RGBA{120, 226, 250, 3},
+	ThemeUnderground: color.RGBA{0, 0, 0, 255},
+	ThemeCastle:      color.RGBA{0, 0, 0, 255},
+}
+
+// undergroundColor : 地下の地面の色（地上の地面のタイルを青く塗る）
+var undergroundColor = color.RGBA{90, 160, 230, 255}
+
+// Course is a stage of the game, with the look of its world
+type Course struct {
+	// Name : コース名（HUDに表示するワールド名）
+	Name string
+	// Theme : テーマ（ThemeOverworld, ThemeUnderground, ThemeCastle）
+	Theme int
+}
+
+// Courses : ゲームのコース（ゴールすると次のコースに進む）
+var Courses = []Course{
+	{Name: "1-1", Theme: ThemeOverworld},
+	{Name: "1-2", Theme: ThemeUnderground},
+	{Name: "1-3", Theme: ThemeOverworld},
+	{Name: "1-4", Theme: ThemeCastle},
+}
+
+// CourseSeed returns the seed of the generation of a course, derived from the seed of the game,
+// so that a course is the same whether it is reached by playing or from the world select
+func CourseSeed(seed int64, course int) int64 {
+	return seed + int64(course)
+}
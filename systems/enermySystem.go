@@ -9,85 +9,389 @@ import (
 const (
 	// EneymyType0 : パックンフラワー
 	EneymyType0 = 0
-	// Type0Count :
-	Type0Count = 128
+	// EneymyType1 : クリボー
+	EneymyType1 = 1
+	// EneymyType2 : ノコノコ
+	EneymyType2 = 2
+	// Type0Time : パックンフラワーが出る（引っ込む、静止する）時間（秒）
+	Type0Time = 2
 	// ExtraSizeXType0 : 余分サイズ
 	ExtraSizeXType0 = 6
 	// ExtraSizeYType0 : 余分サイズ
 	ExtraSizeYType0 = 8
+	// ExtraSizeXType1 : 余分サイズ
+	ExtraSizeXType1 = 7
+	// ExtraSizeYType1 : 余分サイズ
+	ExtraSizeYType1 = 15
+	// ExtraSizeXType2 : 余分サイズ
+	ExtraSizeXType2 = 5
+	// ExtraSizeYType2 : 余分サイズ
+	ExtraSizeYType2 = 8
+	// ExtraSizeXShell : 甲羅の余分サイズ
+	ExtraSizeXShell = 7
+	// ExtraSizeYShell : 甲羅の余分サイズ
+	ExtraSizeYShell = 16
 )
 
-var enermyFile = "./Mario/Characters/Enemies.png"
+const (
+	// Type0Cell : パックンフラワーのセル番号（2コマ）
+	Type0Cell = 7
+	// Type1Cell : クリボーの歩くセル番号（2コマ）
+	Type1Cell = 0
+	// Type1FlatCell : つぶれたクリボーのセル番号
+	Type1FlatCell = 2
+	// Type2Cell : ノコノコの歩くセル番号（2コマ）
+	Type2Cell = 3
+	// Type2ShellCell : 甲羅のセル番号
+	Type2ShellCell = 5
+)
 
-var ifTouched bool
+const (
+	// EnermyStateWait : 画面に入るまで待機中
+	EnermyStateWait = 0
+	// EnermyStateMove : 移動中
+	EnermyStateMove = 1
+	// EnermyStateFlat : 踏まれてつぶれた
+	EnermyStateFlat = 2
+	// EnermyStateShell : 甲羅（停止中）
+	EnermyStateShell = 3
+	// EnermyStateKicked : 甲羅（蹴られて移動中）
+	EnermyStateKicked = 4
+)
 
-// EnetmyPositionType0 : 敵キャラの位置
-var EnetmyPositionType0 []int
+const (
+	// WalkSpeed : 歩く敵キャラの移動速度（px/s）
+	WalkSpeed = 40
+	// ShellSpeed : 蹴られた甲羅の移動速度（px/s）
+	ShellSpeed = 320
+	// EnermyCellTime : 歩く動作の1コマの時間（秒）
+	EnermyCellTime = 0.2
+	// ChompCellTime : パックンフラワーが口を開け閉めする1コマの時間（秒）
+	ChompCellTime = 0.15
+	// KickGraceTime : 甲羅を踏んだ、蹴った直後に甲羅に触れても何も起きない時間（秒）
+	KickGraceTime = 0.25
+	// FlatTime : つぶれたクリボーが消えるまでの時間（秒）
+	FlatTime = 0.5
+	// StompScore : 敵キャラを踏んだ時のスコア
+	StompScore = 100
+	// DefeatScore : ファイアボール、スーパースターで敵キャラを倒した時のスコア
+	DefeatScore = 200
+)
+
+var enermyFile = "./Mario/Characters/Enemies.png"
 
 // Enermy is struct for the EnermySystem
 type Enermy struct {
 	ecs.BasicEntity
 	common.RenderComponent
 	common.SpaceComponent
-	count      int
+	AnimationComponent
+	Collider
+	// 動作の経過時間（秒）
+	elapsed    float32
 	enermyType int
+	// 状態（EnermyStateXxxx）
+	state int
+	// 横方向の速度（右向きが正）
+	velocityX float32
+	// 縦方向の速度（下向きが正）
+	velocityY float32
+	// 踏まれた、蹴られた直後の残り時間（秒）
+	kickTime float32
 }
 
 // EnermySystem creates enemies that disturb the player.
 type EnermySystem struct {
+	// Game : ゲームの状態
+	Game *GameState
+
 	world        *ecs.World
 	enermyEntity []*Enermy
+	player       *PlayerSystem
+	spritesheet  *common.Spritesheet
 }
 
 // Remove removes an Entity from the System
-func (es *EnermySystem) Remove(ecs.BasicEntity) {
+func (es *EnermySystem) Remove(basic ecs.BasicEntity) {
+	index := -1
+	for i, e := range es.enermyEntity {
+		if e.BasicEntity.ID() == basic.ID() {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return
+	}
+	es.enermyEntity = append(es.enermyEntity[:index], es.enermyEntity[index+1:]...)
+	// RenderSystem, CollisionSystemから削除
+	for _, system := range es.world.Systems() {
+		switch sys := system.(type) {
+		case *common.RenderSystem:
+			sys.Remove(basic)
+		case *CollisionSystem:
+			sys.Remove(basic)
+		case *AnimationSystem:
+			sys.Remove(basic)
+		}
+	}
 }
 
 // Update is ran every frame, with `dt` being the time in seconds since the last frame
 func (es *EnermySystem) Update(dt float32) {
+	// プレイ中でなければリターン
+	if es.Game.State() != StatePlaying {
+		return
+	}
+	// プレイヤーとの接触はCollisionSystemで判定する
+	removed := make([]ecs.BasicEntity, 0)
+	for _, entity := range es.enermyEntity {
+		switch entity.enermyType {
+		case EneymyType0:
+			es.updatePiranha(entity, dt)
+		case EneymyType1, EneymyType2:
+			if !es.updateWalker(entity, dt) {
+				removed = append(removed, entity.BasicEntity)
+			}
+		}
+	}
+	// 倒した敵キャラ、落とし穴に落ちた敵キャラを削除
+	for _, basic := range removed {
+		es.Remove(basic)
+	}
+}
 
-	var playerLeftPositionX float32
-	var playerRightPositionX float32
-	var playerBottomPositionY float32
+// updatePiranha moves a Piranha Plant up and down in its pipe
+func (es *EnermySystem) updatePiranha(entity *Enermy, dt float32) {
+	entity.elapsed += dt
+	if entity.elapsed < Type0Time {
+		entity.SpaceComponent.Position.Y = pipePositionY() - CellHeight32*entity.elapsed/Type0Time
+	} else if entity.elapsed < Type0Time*2 {
+		// 一時静止
+		entity.SpaceComponent.Position.Y = pipePositionY() - CellHeight32
+	} else if entity.elapsed < Type0Time*3 {
+		entity.SpaceComponent.Position.Y = pipePositionY() - CellHeight32 + CellHeight32*(entity.elapsed-Type0Time*2)/Type0Time
+	} else {
+		entity.SpaceComponent.Position.Y = pipePositionY()
+		entity.elapsed = 0
+	}
+}
 
-	for _, system := range es.world.Systems() {
-		switch sys := system.(type) {
-		case *PlayerSystem:
-			if ifTouched {
-				if ifGameOver {
-					return
-				}
-				ifTouched = false
-				sys.PlayerDie()
-				ifGameOver = true
+// updateWalker moves a Goomba or a Koopa Troopa (or its shell) along the ground.
+// It returns false when the enemy has to be removed
+func (es *EnermySystem) updateWalker(entity *Enermy, dt float32) bool {
+	// 画面の左右端
+	cameraX := es.player.playerEntity.cameraPositionX
+	left := cameraX - engo.WindowWidth()/2
+	right := cameraX + engo.WindowWidth()/2
+
+	switch entity.state {
+	case EnermyStateWait:
+		// 画面に入ったら動き出す
+		if entity.SpaceComponent.Position.X < right+CellWidth16 {
+			entity.state = EnermyStateMove
+		}
+		return true
+	case EnermyStateFlat:
+		// つぶれたクリボーは一定時間後に消える
+		entity.elapsed += dt
+		return entity.elapsed < FlatTime
+	}
+	// 落とし穴に落ちたか、画面の左に出たら消える
+	if entity.SpaceComponent.Position.Y > engo.WindowHeight() || entity.SpaceComponent.Position.X+CellWidth32 < left {
+		return false
+	}
+
+	switch entity.state {
+	case EnermyStateMove:
+		entity.SpaceComponent.Position.X += entity.velocityX * dt
+		// 進行方向に向ける（右向きは画像を反転）
+		if entity.velocityX > 0 {
+			entity.RenderComponent.Scale.X = -1
+		} else {
+			entity.RenderComponent.Scale.X = 1
+		}
+	case EnermyStateKicked:
+		entity.SpaceComponent.Position.X += entity.velocityX * dt
+	}
+	if entity.kickTime > 0 {
+		entity.kickTime -= dt
+	}
+
+	// 着地はCollisionSystemからの通知で判定する
+	entity.velocityY += Gravity * dt
+	if entity.velocityY > MaxFallSpeed {
+		entity.velocityY = MaxFallSpeed
+	}
+	entity.SpaceComponent.Position.Y += entity.velocityY * dt
+	return true
+}
+
+// onCollision handles the contacts of the enemies reported by the CollisionSystem
+func (es *EnermySystem) onCollision(contact Contact) {
+	if es.Game.State() != StatePlaying {
+		return
+	}
+	var entity *Enermy
+	for _, e := range es.enermyEntity {
+		if e.ID() == contact.Entity.ID() {
+			entity = e
+			break
+		}
+	}
+	if entity == nil {
+		return
+	}
+	switch contact.Group {
+	case GroupPlayer:
+		es.onPlayerContact(entity, contact)
+	case GroupFireball:
+		// ファイアボールで倒される（つぶれた敵キャラは除く）
+		if entity.state != EnermyStateFlat {
+			es.defeat(entity)
+		}
+	case GroupEnemy:
+		// 蹴られた甲羅は他の敵キャラを倒す（それ以外の敵キャラ同士は接触しない）
+		if entity.state == EnermyStateKicked {
+			es.onShellContact(contact.Other)
+		}
+	case GroupTerrain:
+		switch contact.Side {
+		case SideLeft:
+			// 土管や壁に当たったら向きを変える
+			if entity.velocityX < 0 {
+				entity.velocityX = -entity.velocityX
+			}
+		case SideRight:
+			if entity.velocityX > 0 {
+				entity.velocityX = -entity.velocityX
+			}
+		case SideBottom:
+			// 着地
+			entity.velocityY = 0
+		case SideTop:
+			if entity.velocityY < 0 {
+				entity.velocityY = 0
 			}
-			playerLeftPositionX = sys.playerEntity.LeftPositionX
-			playerRightPositionX = sys.playerEntity.RightPositionX
-			playerBottomPositionY = sys.playerEntity.SpaceComponent.Position.Y + float32(CellHeight32)
 		}
 	}
-	if ifGameOver {
+}
+
+// onPlayerContact handles the contact of the player with the enemy
+func (es *EnermySystem) onPlayerContact(entity *Enermy, contact Contact) {
+	// スーパースターで無敵のプレイヤーに触れると倒される
+	if es.player.HasStar() && entity.state != EnermyStateFlat {
+		es.defeat(entity)
 		return
 	}
-	for _, entity := range es.enermyEntity {
+	switch entity.state {
+	case EnermyStateMove:
+		// プレイヤーに上から踏まれた（パックンフラワーは踏めない）
+		if es.isStomped(contact) && entity.enermyType != EneymyType0 {
+			es.stomp(entity)
+			return
+		}
+	case EnermyStateShell:
+		// 止まっている甲羅は蹴る（踏んだ直後は蹴らない）
+		if entity.kickTime <= 0 {
+			es.kick(entity)
+		}
+		return
+	case EnermyStateKicked:
+		// 動いている甲羅は踏むと止まる
+		if es.isStomped(contact) {
+			entity.state = EnermyStateShell
+			entity.kickTime = KickGraceTime
+			entity.velocityX = 0
+			entity.Collider.SameGroup = false
+			es.player.PlayerBounce()
+			return
+		}
+		// 蹴った直後は当たらない
+		if entity.kickTime > 0 {
+			return
+		}
+	default:
+		// つぶれた敵キャラなどには当たらない
+		return
+	}
+	// 敵キャラに触れたらダメージ（ちびマリオは死亡）
+	es.player.PlayerDamage()
+}
 
-		if getMakingInfo(EnetmyPositionType0, int(playerLeftPositionX)) || getMakingInfo(EnetmyPositionType0, int(playerRightPositionX)) {
-			if pipePositionY >= playerBottomPositionY && entity.SpaceComponent.Position.Y+ExtraSizeYType0 < playerBottomPositionY {
-				ifTouched = true
+// onShellContact defeats the enemy hit by a kicked shell (except a flattened Goomba)
+func (es *EnermySystem) onShellContact(other *ecs.BasicEntity) {
+	for _, e := range es.enermyEntity {
+		if e.ID() == other.ID() {
+			if e.state != EnermyStateFlat {
+				es.defeat(e)
 			}
+			return
 		}
-		if entity.enermyType == EneymyType0 {
-			if entity.count < Type0Count {
-				entity.SpaceComponent.Position.Y = pipePositionY - float32(entity.count/4)
-			} else if entity.count < Type0Count*2 {
-				// 一時静止
-			} else if entity.count < Type0Count*3 {
-				entity.SpaceComponent.Position.Y = pipePositionY - CellHeight32 + float32((entity.count-Type0Count*2)/4)
-			} else {
-				entity.count = 0
-			}
-			entity.count++
+	}
+}
+
+// defeat removes an enemy hit by a fireball, a kicked shell or the invincible player
+func (es *EnermySystem) defeat(entity *Enermy) {
+	es.Game.Progress.AddScore(DefeatScore)
+	es.Remove(entity.BasicEntity)
+}
+
+// isStomped returns whether the player touching an enemy came onto its head:
+// the bottom of the player was above the top of the enemy before the contact
+func (es *EnermySystem) isStomped(contact Contact) bool {
+	return contact.OtherLast.Max.Y <= contact.Last.Min.Y
+}
+
+// stomp flattens a Goomba, or makes a Koopa Troopa retreat into its shell.
+// The player bounces and scores
+func (es *EnermySystem) stomp(entity *Enermy) {
+	es.player.PlayerBounce()
+	es.Game.Progress.AddScore(StompScore)
+	entity.velocityX = 0
+	entity.elapsed = 0
+	entity.RenderComponent.Scale.X = 1
+	switch entity.enermyType {
+	case EneymyType1:
+		entity.state = EnermyStateFlat
+		entity.AnimationComponent.Play(&entity.RenderComponent, AnimationFlat)
+	case EneymyType2:
+		entity.state = EnermyStateShell
+		entity.kickTime = KickGraceTime
+		entity.AnimationComponent.Play(&entity.RenderComponent, AnimationShell)
+		entity.Collider.Inset = engo.Point{X: ExtraSizeXShell, Y: ExtraSizeYShell}
+	}
+}
+
+// kick sends a shell away from the player, defeating the enemies on its way
+func (es *EnermySystem) kick(entity *Enermy) {
+	entity.state = EnermyStateKicked
+	entity.kickTime = KickGraceTime
+	entity.Collider.SameGroup = true
+	if entity.SpaceComponent.Position.X < es.player.playerEntity.SpaceComponent.Position.X {
+		entity.velocityX = -ShellSpeed
+	} else {
+		entity.velocityX = ShellSpeed
+	}
+}
+
+// enermyAnimations returns the animations of an enemy type, the first one being its default animation
+func enermyAnimations(enermyType int) []*SpriteAnimation {
+	switch enermyType {
+	case EneymyType1:
+		return []*SpriteAnimation{
+			{Animation: common.Animation{Name: AnimationWalk, Frames: []int{Type1Cell, Type1Cell + 1}, Loop: true}, Rate: EnermyCellTime},
+			{Animation: common.Animation{Name: AnimationFlat, Frames: []int{Type1FlatCell}, Loop: true}, Rate: EnermyCellTime},
 		}
+	case EneymyType2:
+		return []*SpriteAnimation{
+			{Animation: common.Animation{Name: AnimationWalk, Frames: []int{Type2Cell, Type2Cell + 1}, Loop: true}, Rate: EnermyCellTime},
+			{Animation: common.Animation{Name: AnimationShell, Frames: []int{Type2ShellCell}, Loop: true}, Rate: EnermyCellTime},
+		}
+	}
+	// パックンフラワー
+	return []*SpriteAnimation{
+		{Animation: common.Animation{Name: AnimationChomp, Frames: []int{Type0Cell, Type0Cell + 1}, Loop: true}, Rate: ChompCellTime},
 	}
 }
 
@@ -95,54 +399,98 @@ func (es *EnermySystem) Update(dt float32) {
 func (es *EnermySystem) New(w *ecs.World) {
 	//　Worldの追加
 	es.world = w
-	ifTouched = false
-	ifGameOver = false
+	// プレイヤーの取得
+	for _, system := range es.world.Systems() {
+		switch sys := system.(type) {
+		case *PlayerSystem:
+			es.player = sys
+		}
+	}
+	// 接触の通知
+	engo.Mailbox.Listen("CollisionMessage", func(msg engo.Message) {
+		contact, ok := msg.(CollisionMessage)
+		if !ok {
+			return
+		}
+		es.onCollision(contact.Contact)
+	})
 	// Enermy配列作成
 	Enemies := make([]*Enermy, 0)
 
 	// スプライトシートの作成
-	Spritesheet32x32 := common.NewSpritesheetWithBorderFromFile(enermyFile, CellWidth32, CellHeight32, 0, 0)
+	es.spritesheet = common.NewSpritesheetWithBorderFromFile(enermyFile, CellWidth32, CellHeight32, 0, 0)
 
-	//randomNum := rand.Intn(10)
-	for i := 0; i <= TileNum; i++ {
-		if getMakingInfo(PipePoint, i*CellWidth16) {
-			enermy := &Enermy{BasicEntity: ecs.NewBasic()}
+	for _, spawn := range es.Game.Level.Enemies {
+		enermy := &Enermy{BasicEntity: ecs.NewBasic()}
 
-			// SpaceComponent
-			enermy.SpaceComponent = common.SpaceComponent{
-				Position: engo.Point{X: float32(i * CellWidth16), Y: pipePositionY},
-			}
+		// 初期化
+		enermy.elapsed = 0
+		enermy.enermyType = spawn.Type
+		enermy.state = EnermyStateMove
 
-			// RenderComponent
-			enermy.RenderComponent = common.RenderComponent{
-				Drawable: Spritesheet32x32.Cell(7),
-				Scale:    engo.Point{X: 1, Y: 1},
+		// 種類毎の処理
+		positionY := pipePositionY()
+		switch spawn.Type {
+		case EneymyType0:
+			// 土管の中を動くため地形とは衝突しない
+			enermy.Collider = Collider{
+				Group: GroupEnemy,
+				Inset: engo.Point{X: ExtraSizeXType0, Y: ExtraSizeYType0},
 			}
-			enermy.RenderComponent.SetZIndex(6)
-
-			// 初期化
-			enermy.count = 0
+		case EneymyType1, EneymyType2:
+			// 地面の上を歩き、画面に入るまで待機する
+			positionY = groundPositionY()
+			enermy.state = EnermyStateWait
+			enermy.velocityX = -WalkSpeed
+			enermy.Collider = Collider{
+				Group:   GroupEnemy,
+				Inset:   engo.Point{X: ExtraSizeXType1, Y: ExtraSizeYType1},
+				Terrain: true,
+			}
+			if spawn.Type == EneymyType2 {
+				enermy.Collider.Inset = engo.Point{X: ExtraSizeXType2, Y: ExtraSizeYType2}
+			}
+		default:
+			continue
+		}
 
-			// コンポーネントセット
-			Enemies = append(Enemies, enermy)
+		// SpaceComponent
+		enermy.SpaceComponent = common.SpaceComponent{
+			Position: engo.Point{X: float32(spawn.X * CellWidth16), Y: positionY},
+			Width:    CellWidth32,
+			Height:   CellHeight32,
+		}
 
-			// 敵キャラの位置記録
-			for j := 0; j < CellWidth32; j++ {
-				if j > ExtraSizeXType0 && j < CellWidth32-ExtraSizeXType0 {
-					EnetmyPositionType0 = append(EnetmyPositionType0, i*CellWidth16+j)
-				}
-			}
-			i++
+		// RenderComponent
+		enermy.RenderComponent = common.RenderComponent{
+			Scale: engo.Point{X: 1, Y: 1},
 		}
+		enermy.RenderComponent.SetZIndex(6)
+
+		// AnimationComponent（最初のアニメーションを表示する）
+		animations := enermyAnimations(spawn.Type)
+		enermy.AnimationComponent = NewAnimationComponent(es.spritesheet, animations)
+		enermy.AnimationComponent.Play(&enermy.RenderComponent, animations[0].Name)
+
+		// コンポーネントセット
+		Enemies = append(Enemies, enermy)
 	}
-	// RenderSystemに追加
+	es.enermyEntity = Enemies
+	// RenderSystem, CollisionSystem, AnimationSystemに追加
 	for _, system := range es.world.Systems() {
 		switch sys := system.(type) {
 		case *common.RenderSystem:
 			for _, v := range Enemies {
-				es.enermyEntity = append(es.enermyEntity, v)
 				sys.Add(&v.BasicEntity, &v.RenderComponent, &v.SpaceComponent)
 			}
+		case *CollisionSystem:
+			for _, v := range Enemies {
+				sys.Add(&v.BasicEntity, &v.SpaceComponent, &v.Collider)
+			}
+		case *AnimationSystem:
+			for _, v := range Enemies {
+				sys.Add(&v.BasicEntity, &v.AnimationComponent.AnimationComponent, &v.RenderComponent)
+			}
 		}
 	}
 }
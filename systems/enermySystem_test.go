@@ -0,0 +1,210 @@
+package systems
+
+import (
+	"testing"
+
+	"github.com/EngoEngine/engo"
+)
+
+// enemiesLevelFile : クリボー（タイル12）、ノコノコ（タイル20）、クリボー（タイル28）だけがいる平らなコース
+const enemiesLevelFile = "levels/enemies.json"
+
+// testEnemies returns the EnermySystem of the simulation and its enemies of the type, in the order of the level
+func testEnemies(sim *Simulation, enermyType int) (*EnermySystem, []*Enermy) {
+	var es *EnermySystem
+	for _, system := range sim.world.Systems() {
+		switch sys := system.(type) {
+		case *EnermySystem:
+			es = sys
+		}
+	}
+	enemies := make([]*Enermy, 0)
+	for _, e := range es.enermyEntity {
+		if e.enermyType == enermyType {
+			enemies = append(enemies, e)
+		}
+	}
+	return es, enemies
+}
+
+// alive returns whether the enemy is still in the EnermySystem
+func alive(es *EnermySystem, entity *Enermy) bool {
+	for _, e := range es.enermyEntity {
+		if e == entity {
+			return true
+		}
+	}
+	return false
+}
+
+func TestKickedShellDefeatsEnemies(t *testing.T) {
+	sim := newTestSimulation(1, enemiesLevelFile)
+	sim.Start()
+	es, koopas := testEnemies(sim, EneymyType2)
+	_, goombas := testEnemies(sim, EneymyType1)
+	if len(koopas) != 1 || len(goombas) != 2 {
+		t.Fatalf("%d koopas and %d goombas, want 1 and 2", len(koopas), len(goombas))
+	}
+
+	// ノコノコを甲羅にして右のクリボーに向けて蹴る
+	shell := koopas[0]
+	es.stomp(shell)
+	es.kick(shell)
+	if shell.velocityX <= 0 {
+		t.Fatalf("shell kicked at %v px/s, want to the right", shell.velocityX)
+	}
+	score := sim.Progress().Score
+	runUntil(sim, 120, func() bool { return !alive(es, goombas[1]) })
+	if alive(es, goombas[1]) {
+		t.Fatalf("goomba at %v not defeated by the shell at %v", goombas[1].Position, shell.Position)
+	}
+	if !alive(es, shell) || shell.state != EnermyStateKicked {
+		t.Errorf("the shell stopped after defeating the goomba")
+	}
+	if got := sim.Progress().Score - score; got != DefeatScore {
+		t.Errorf("score +%d, want +%d", got, DefeatScore)
+	}
+	// 止まっている甲羅は他の敵キャラを倒さない
+	shell.state = EnermyStateShell
+	shell.velocityX = 0
+	shell.Collider.SameGroup = false
+	shell.Position.X = goombas[0].Position.X
+	sim.Step(1)
+	if !alive(es, goombas[0]) {
+		t.Errorf("goomba defeated by a shell at rest")
+	}
+}
+
+// playerContact reports a contact of the player coming from the side of the enemy to the EnermySystem.
+// Before the contact the player was next to that side (SideNone: they already overlapped)
+func playerContact(sim *Simulation, es *EnermySystem, entity *Enermy, side int) {
+	last := engo.AABB{Min: entity.Position, Max: engo.Point{X: entity.Position.X + CellWidth16, Y: entity.Position.Y + CellHeight16}}
+	offset := engo.Point{}
+	switch side {
+	case SideTop:
+		offset.Y = -CellHeight16
+	case SideBottom:
+		offset.Y = CellHeight16
+	case SideLeft:
+		offset.X = -CellWidth16
+	case SideRight:
+		offset.X = CellWidth16
+	}
+	otherLast := engo.AABB{
+		Min: engo.Point{X: last.Min.X + offset.X, Y: last.Min.Y + offset.Y},
+		Max: engo.Point{X: last.Max.X + offset.X, Y: last.Max.Y + offset.Y},
+	}
+	es.onCollision(Contact{Entity: &entity.BasicEntity, Other: &sim.player.playerEntity.BasicEntity, Group: GroupPlayer, Side: side, Last: last, OtherLast: otherLast})
+}
+
+func TestStompDefeatsGoomba(t *testing.T) {
+	sim := newTestSimulation(1, enemiesLevelFile)
+	sim.Start()
+	es, goombas := testEnemies(sim, EneymyType1)
+	goomba := goombas[0]
+
+	// プレイヤーが上から触れると踏む
+	score := sim.Progress().Score
+	playerContact(sim, es, goomba, SideTop)
+	if goomba.state != EnermyStateFlat {
+		t.Errorf("goomba state %d, want EnermyStateFlat", goomba.state)
+	}
+	if got := sim.Progress().Score - score; got != StompScore {
+		t.Errorf("score +%d, want +%d", got, StompScore)
+	}
+	if sim.player.playerEntity.velocityY >= 0 {
+		t.Errorf("player velocity %v after the stomp, want a bounce", sim.player.playerEntity.velocityY)
+	}
+	if sim.State() != StatePlaying {
+		t.Errorf("state %d after the stomp, want StatePlaying", sim.State())
+	}
+}
+
+func TestStompByFalling(t *testing.T) {
+	sim := newTestSimulation(1, enemiesLevelFile)
+	sim.Start()
+	es, goombas := testEnemies(sim, EneymyType1)
+	goomba := goombas[0]
+
+	// クリボーの真上から落とす（接触した側はCollisionSystemが判定する）
+	player := sim.player.playerEntity
+	player.SpaceComponent.Position = goomba.Position
+	player.SpaceComponent.Position.Y -= CellHeight32 * 2
+	runUntil(sim, 60, func() bool { return goomba.state != EnermyStateMove || sim.State() != StatePlaying })
+	if goomba.state != EnermyStateFlat || !alive(es, goomba) {
+		t.Errorf("goomba state %d, want EnermyStateFlat", goomba.state)
+	}
+	if sim.State() != StatePlaying {
+		t.Errorf("state %d after falling onto the goomba, want StatePlaying", sim.State())
+	}
+}
+
+func TestStompWhileRising(t *testing.T) {
+	sim := newTestSimulation(1, enemiesLevelFile)
+	sim.Start()
+	es, goombas := testEnemies(sim, EneymyType1)
+	goomba := goombas[0]
+
+	// 上昇中でも前フレームにクリボーの頭より上にいれば踏む（踏むかは速度ではなく位置で決まる）
+	sim.player.playerEntity.velocityY = -100
+	playerContact(sim, es, goomba, SideTop)
+	if goomba.state != EnermyStateFlat {
+		t.Errorf("goomba state %d, want EnermyStateFlat", goomba.state)
+	}
+	if sim.State() != StatePlaying {
+		t.Errorf("state %d after the stomp, want StatePlaying", sim.State())
+	}
+}
+
+func TestSideContactHurtsPlayer(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		side int
+	}{
+		{"left", SideLeft},
+		{"right", SideRight},
+		{"bottom", SideBottom},
+		{"already overlapping", SideNone},
+	} {
+		sim := newTestSimulation(1, enemiesLevelFile)
+		sim.Start()
+		es, goombas := testEnemies(sim, EneymyType1)
+		goomba := goombas[0]
+
+		// ちびマリオは死亡する
+		playerContact(sim, es, goomba, tt.side)
+		if sim.State() != StateDying {
+			t.Errorf("%s: state %d, want StateDying", tt.name, sim.State())
+		}
+		if goomba.state != EnermyStateMove {
+			t.Errorf("%s: goomba state %d, want EnermyStateMove", tt.name, goomba.state)
+		}
+	}
+
+	// スーパーマリオはちびマリオに戻る
+	sim := newTestSimulation(1, enemiesLevelFile)
+	sim.Start()
+	es, goombas := testEnemies(sim, EneymyType1)
+	sim.player.PlayerGetItem(ItemMushroom)
+	playerContact(sim, es, goombas[0], SideLeft)
+	if sim.State() != StatePlaying || sim.player.Power() != PowerSmall {
+		t.Errorf("super player hit: state %d power %d, want StatePlaying PowerSmall", sim.State(), sim.player.Power())
+	}
+}
+
+func TestStarDefeatsEnemies(t *testing.T) {
+	sim := newTestSimulation(1, enemiesLevelFile)
+	sim.Start()
+	es, goombas := testEnemies(sim, EneymyType1)
+
+	// スーパースターで無敵の間は触れた敵キャラを倒す
+	sim.player.PlayerGetItem(ItemStar)
+	score := sim.Progress().Score
+	playerContact(sim, es, goombas[0], SideLeft)
+	if alive(es, goombas[0]) || sim.State() != StatePlaying {
+		t.Errorf("goomba alive %v, state %d after touching it with a star, want false StatePlaying", alive(es, goombas[0]), sim.State())
+	}
+	if got := sim.Progress().Score - score; got != DefeatScore {
+		t.Errorf("score +%d, want +%d", got, DefeatScore)
+	}
+}
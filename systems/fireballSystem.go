@@ -0,0 +1,202 @@
+package systems
+
+import (
+	"image/color"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/EngoEngine/engo"
+	"github.com/EngoEngine/engo/common"
+)
+
+const (
+	// FireballSize : ファイアボールの大きさ（px）
+	FireballSize = 8
+	// FireballSpeed : ファイアボールの横方向の速度（px/s）
+	FireballSpeed = 300
+	// FireballBounceSpeed : ファイアボールが跳ねる初速（px/s）
+	FireballBounceSpeed = 240
+	// MaxFireballs : 同時に投げられるファイアボールの数
+	MaxFireballs = 2
+)
+
+// Fireball is struct for the FireballSystem
+type Fireball struct {
+	ecs.BasicEntity
+	common.RenderComponent
+	common.SpaceComponent
+	Collider
+	// 横方向の速度（右向きが正）
+	velocityX float32
+	// 縦方向の速度（下向きが正）
+	velocityY float32
+}
+
+// FireballSystem throws the bouncing fireballs of the fire player
+type FireballSystem struct {
+	// Game : ゲームの状態
+	Game *GameState
+
+	world          *ecs.World
+	fireballEntity []*Fireball
+	player         *PlayerSystem
+	input          *InputSystem
+}
+
+// Remove removes an Entity from the System
+func (fs *FireballSystem) Remove(basic ecs.BasicEntity) {
+	index := -1
+	for i, e := range fs.fireballEntity {
+		if e.BasicEntity.ID() == basic.ID() {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return
+	}
+	fs.fireballEntity = append(fs.fireballEntity[:index], fs.fireballEntity[index+1:]...)
+	// RenderSystem, CollisionSystemから削除
+	for _, system := range fs.world.Systems() {
+		switch sys := system.(type) {
+		case *common.RenderSystem:
+			sys.Remove(basic)
+		case *CollisionSystem:
+			sys.Remove(basic)
+		}
+	}
+}
+
+// Update is ran every frame, with `dt` being the time in seconds since the last frame
+func (fs *FireballSystem) Update(dt float32) {
+	// プレイ中でなければリターン
+	if fs.Game.State() != StatePlaying {
+		return
+	}
+	// ファイアマリオはファイアボールを投げる
+	if fs.input.JustPressed(ButtonFire) && fs.player.Power() == PowerFire && len(fs.fireballEntity) < MaxFireballs {
+		fs.throw()
+	}
+
+	// 画面の左右端
+	cameraX := fs.player.playerEntity.cameraPositionX
+	left := cameraX - engo.WindowWidth()/2
+	right := cameraX + engo.WindowWidth()/2
+
+	removed := make([]ecs.BasicEntity, 0)
+	for _, fireball := range fs.fireballEntity {
+		// 着地はCollisionSystemからの通知で判定する
+		fireball.SpaceComponent.Position.X += fireball.velocityX * dt
+		fireball.velocityY += Gravity * dt
+		if fireball.velocityY > MaxFallSpeed {
+			fireball.velocityY = MaxFallSpeed
+		}
+		fireball.SpaceComponent.Position.Y += fireball.velocityY * dt
+		// 画面の外に出たら消える
+		position := fireball.SpaceComponent.Position
+		if position.X+FireballSize < left || position.X > right || position.Y > engo.WindowHeight() {
+			removed = append(removed, fireball.BasicEntity)
+		}
+	}
+	for _, basic := range removed {
+		fs.Remove(basic)
+	}
+}
+
+// throw throws a fireball in front of the player
+func (fs *FireballSystem) throw() {
+	player := fs.player.playerEntity
+	// プレイヤーの向き（左向きは画像を反転している）
+	direction := float32(1)
+	if player.RenderComponent.Scale.X < 0 {
+		direction = -1
+	}
+
+	fireball := &Fireball{BasicEntity: ecs.NewBasic()}
+	fireball.SpaceComponent = common.SpaceComponent{
+		Position: engo.Point{
+			X: player.SpaceComponent.Position.X + (CellWidth32-FireballSize)/2 + direction*CellWidth16/2,
+			Y: player.SpaceComponent.Position.Y + CellHeight16/2,
+		},
+		Width:  FireballSize,
+		Height: FireballSize,
+	}
+	fireball.RenderComponent = common.RenderComponent{
+		Drawable: common.Circle{},
+		Color:    color.RGBA{255, 120, 0, 255},
+		Scale:    engo.Point{X: 1, Y: 1},
+	}
+	fireball.RenderComponent.SetZIndex(6)
+	fireball.velocityX = FireballSpeed * direction
+	fireball.Collider = Collider{
+		Group:   GroupFireball,
+		Terrain: true,
+	}
+
+	fs.fireballEntity = append(fs.fireballEntity, fireball)
+	for _, system := range fs.world.Systems() {
+		switch sys := system.(type) {
+		case *common.RenderSystem:
+			sys.Add(&fireball.BasicEntity, &fireball.RenderComponent, &fireball.SpaceComponent)
+		case *CollisionSystem:
+			sys.Add(&fireball.BasicEntity, &fireball.SpaceComponent, &fireball.Collider)
+		}
+	}
+}
+
+// onCollision handles the contacts of the fireballs reported by the CollisionSystem
+func (fs *FireballSystem) onCollision(contact Contact) {
+	if fs.Game.State() != StatePlaying {
+		return
+	}
+	var fireball *Fireball
+	for _, e := range fs.fireballEntity {
+		if e.ID() == contact.Entity.ID() {
+			fireball = e
+			break
+		}
+	}
+	if fireball == nil {
+		return
+	}
+	switch contact.Group {
+	case GroupEnemy:
+		// 敵キャラに当たったら消える（敵キャラはEnermySystemで倒される）
+		fs.Remove(fireball.BasicEntity)
+	case GroupTerrain:
+		switch contact.Side {
+		case SideBottom:
+			// 地面で跳ねる
+			fireball.velocityY = -FireballBounceSpeed
+		case SideTop:
+			if fireball.velocityY < 0 {
+				fireball.velocityY = 0
+			}
+		case SideLeft, SideRight:
+			// 土管や壁に当たったら消える
+			fs.Remove(fireball.BasicEntity)
+		}
+	}
+}
+
+// New is the initialisation of the System
+func (fs *FireballSystem) New(w *ecs.World) {
+	//　Worldの追加
+	fs.world = w
+	// 入力、プレイヤーの取得
+	for _, system := range fs.world.Systems() {
+		switch sys := system.(type) {
+		case *InputSystem:
+			fs.input = sys
+		case *PlayerSystem:
+			fs.player = sys
+		}
+	}
+	// 接触の通知
+	engo.Mailbox.Listen("CollisionMessage", func(msg engo.Message) {
+		contact, ok := msg.(CollisionMessage)
+		if !ok {
+			return
+		}
+		fs.onCollision(contact.Contact)
+	})
+}
@@ -0,0 +1,88 @@
+package systems
+
+import (
+	"testing"
+
+	"github.com/EngoEngine/engo"
+)
+
+// testFireballs returns the FireballSystem of the simulation
+func testFireballs(sim *Simulation) *FireballSystem {
+	var fs *FireballSystem
+	for _, system := range sim.world.Systems() {
+		switch sys := system.(type) {
+		case *FireballSystem:
+			fs = sys
+		}
+	}
+	return fs
+}
+
+// firePlayer makes the player of the simulation a fire player
+func firePlayer(sim *Simulation) {
+	sim.player.PlayerGetItem(ItemMushroom)
+	sim.player.PlayerGetItem(ItemFireFlower)
+}
+
+func TestFireballLimit(t *testing.T) {
+	sim := newTestSimulation(1, flatLevelFile)
+	sim.Start()
+	fs := testFireballs(sim)
+	firePlayer(sim)
+
+	// 同時に投げられるのはMaxFireballs個まで
+	for i := 0; i < MaxFireballs+1; i++ {
+		sim.Run(1, ButtonFire)
+		sim.Step(1)
+	}
+	if got := len(fs.fireballEntity); got != MaxFireballs {
+		t.Fatalf("%d fireballs after %d throws, want %d", got, MaxFireballs+1, MaxFireballs)
+	}
+	// 消えた後はまた投げられる
+	runUntil(sim, 300, func() bool { return len(fs.fireballEntity) == 0 })
+	sim.Run(1, ButtonFire)
+	if got := len(fs.fireballEntity); got != 1 {
+		t.Errorf("%d fireballs after the others are gone, want 1", got)
+	}
+
+	// ちびマリオは投げられない
+	sim = newTestSimulation(1, flatLevelFile)
+	sim.Start()
+	sim.Run(1, ButtonFire)
+	if fs := testFireballs(sim); len(fs.fireballEntity) != 0 {
+		t.Errorf("%d fireballs thrown by the small player, want 0", len(fs.fireballEntity))
+	}
+}
+
+func TestFireballBounce(t *testing.T) {
+	sim := newTestSimulation(1, flatLevelFile)
+	sim.Start()
+	fs := testFireballs(sim)
+	firePlayer(sim)
+
+	sim.Run(1, ButtonFire)
+	if len(fs.fireballEntity) != 1 {
+		t.Fatalf("%d fireballs, want 1", len(fs.fireballEntity))
+	}
+	fireball := fs.fireballEntity[0]
+	if fireball.velocityX != FireballSpeed {
+		t.Errorf("fireball speed %v, want %v to the right", fireball.velocityX, FireballSpeed)
+	}
+
+	// 地面で跳ねながら進み、地面より下には落ちない
+	ground := engo.WindowHeight() - TileDepth*CellHeight16
+	bounces := 0
+	for i := 0; i < 60 && len(fs.fireballEntity) == 1; i++ {
+		falling := fireball.velocityY > 0
+		sim.Step(1)
+		if falling && fireball.velocityY < 0 {
+			bounces++
+		}
+		if bottom := fireball.Position.Y + FireballSize; bottom > ground+1 {
+			t.Fatalf("fireball bottom at %v, below the ground %v", bottom, ground)
+		}
+	}
+	if bounces < 2 {
+		t.Errorf("fireball bounced %d times, want at least 2", bounces)
+	}
+}
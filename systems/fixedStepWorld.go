@@ -0,0 +1,58 @@
+package systems
+
+import (
+	"github.com/EngoEngine/ecs"
+	"github.com/EngoEngine/engo/common"
+)
+
+const (
+	// StepTime : 1ステップの時間（秒）
+	StepTime = float32(1) / 60
+	// MaxStepsPerFrame : 1フレームで進める最大ステップ数
+	MaxStepsPerFrame = 5
+)
+
+// FixedStepWorld is an ecs.World updating the game systems with a fixed timestep,
+// so that the simulation is the same whatever the frame rate.
+// The RenderSystem is updated once per frame.
+type FixedStepWorld struct {
+	ecs.World
+	// 未処理の時間
+	accumulator float32
+	// 停止したか
+	stopped bool
+}
+
+// Stop stops updating the world immediately, even in the middle of a step.
+// It is used when the scene is rebuilt from one of the systems.
+func (w *FixedStepWorld) Stop() {
+	w.stopped = true
+}
+
+// Update is ran every frame, with `dt` being the time in seconds since the last frame
+func (w *FixedStepWorld) Update(dt float32) {
+	w.accumulator += dt
+	// 処理が遅れた場合に追いつこうとして更に遅れないようにする
+	if w.accumulator > StepTime*MaxStepsPerFrame {
+		w.accumulator = StepTime * MaxStepsPerFrame
+	}
+	for w.accumulator >= StepTime {
+		for _, system := range w.Systems() {
+			if w.stopped {
+				return
+			}
+			if _, ok := system.(*common.RenderSystem); !ok {
+				system.Update(StepTime)
+			}
+		}
+		w.accumulator -= StepTime
+	}
+	if w.stopped {
+		return
+	}
+	for _, system := range w.Systems() {
+		if sys, ok := system.(*common.RenderSystem); ok {
+			sys.Update(dt)
+		}
+	}
+}
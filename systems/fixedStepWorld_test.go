@@ -0,0 +1,74 @@
+package systems
+
+import (
+	"testing"
+
+	"github.com/EngoEngine/ecs"
+)
+
+// stepCounter is a system counting the steps of the world
+type stepCounter struct {
+	steps int
+	// 最後のステップの時間
+	dt float32
+}
+
+func (c *stepCounter) Update(dt float32) {
+	c.steps++
+	c.dt = dt
+}
+
+func (*stepCounter) Remove(ecs.BasicEntity) {}
+
+func TestFixedStepWorldSteps(t *testing.T) {
+	tests := []struct {
+		name string
+		// フレームの時間とフレーム数
+		dt     float32
+		frames int
+		want   int
+	}{
+		{"60 FPS", StepTime, 60, 60},
+		{"30 FPS", StepTime * 2, 30, 60},
+		{"120 FPS", StepTime / 2, 120, 60},
+		{"no time", 0, 10, 0},
+		// 遅れたフレームでもMaxStepsPerFrameまでしか進めない
+		{"one slow frame", 1, 1, MaxStepsPerFrame},
+		{"slow frames", 0.5, 4, 4 * MaxStepsPerFrame},
+	}
+	for _, tt := range tests {
+		w := &FixedStepWorld{}
+		counter := &stepCounter{}
+		w.AddSystem(counter)
+		for i := 0; i < tt.frames; i++ {
+			w.Update(tt.dt)
+		}
+		// 浮動小数点の誤差で1ステップずれることは許す
+		if counter.steps < tt.want-1 || counter.steps > tt.want {
+			t.Errorf("%s: %d steps, want %d", tt.name, counter.steps, tt.want)
+		}
+		if counter.steps > 0 && counter.dt != StepTime {
+			t.Errorf("%s: step of %vs, want %vs", tt.name, counter.dt, StepTime)
+		}
+	}
+}
+
+func TestFixedStepWorldDropsBacklog(t *testing.T) {
+	w := &FixedStepWorld{}
+	counter := &stepCounter{}
+	w.AddSystem(counter)
+
+	// 1秒止まった後は遅れを取り戻そうとせず、次のフレームから通常通り進める
+	w.Update(1)
+	if counter.steps != MaxStepsPerFrame {
+		t.Fatalf("%d steps after a frame of 1s, want %d", counter.steps, MaxStepsPerFrame)
+	}
+	w.Update(StepTime / 2)
+	if counter.steps != MaxStepsPerFrame {
+		t.Errorf("%d steps after half a step, want %d", counter.steps, MaxStepsPerFrame)
+	}
+	w.Update(StepTime / 2)
+	if counter.steps != MaxStepsPerFrame+1 {
+		t.Errorf("%d steps after a full step, want %d", counter.steps, MaxStepsPerFrame+1)
+	}
+}
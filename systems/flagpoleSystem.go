@@ -0,0 +1,245 @@
+package systems
+
+import (
+	"image/color"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/EngoEngine/engo"
+	"github.com/EngoEngine/engo/common"
+)
+
+const (
+	// FlagpoleTileNum : 城からゴールのポールまでのタイル数
+	FlagpoleTileNum = 6
+	// FlagpoleWidth : ゴールのポールの幅（px）
+	FlagpoleWidth = 2
+	// FlagpoleHeight : ゴールのポールの高さ（px）
+	FlagpoleHeight = 144
+	// FlagpoleBallSize : ポールの先の玉の大きさ（px）
+	FlagpoleBallSize = 8
+	// GoalFlagWidth : ゴールの旗の幅（px）
+	GoalFlagWidth = 16
+	// GoalFlagHeight : ゴールの旗の高さ（px）
+	GoalFlagHeight = 12
+	// SlideSpeed : ポールを滑り降りる速度（px/s）
+	SlideSpeed = 180
+	// FlagpoleWaitTime : 滑り降りてから城に歩き出すまでの時間（秒）
+	FlagpoleWaitTime = 0.3
+	// WalkInSpeed : 城に歩いて入る速度（px/s）
+	WalkInSpeed = 120
+	// TimeCountSpeed : 残り時間をスコアに換算する速度（秒/s）
+	TimeCountSpeed = 120
+	// TimeBonusScore : 残り時間1秒毎のスコア
+	TimeBonusScore = 50
+)
+
+const (
+	// FlagpoleSlide : ポールを滑り降りる
+	FlagpoleSlide = 0
+	// FlagpoleWait : 降りた後の待ち
+	FlagpoleWait = 1
+	// FlagpoleWalk : 城に歩いて入る
+	FlagpoleWalk = 2
+	// FlagpoleCount : 残り時間をスコアに換算する
+	FlagpoleCount = 3
+)
+
+var (
+	// flagpoleColor : ポールの色
+	flagpoleColor = color.RGBA{120, 200, 80, 255}
+	// goalFlagColor : ゴールの旗の色
+	goalFlagColor = color.RGBA{255, 255, 255, 255}
+)
+
+// FlagpoleSystem places the flagpole in front of the castle and plays the goal sequence:
+// the player grabs the pole, slides down, walks into the castle, and the time left is scored
+type FlagpoleSystem struct {
+	// Game : ゲームの状態
+	Game *GameState
+
+	world  *ecs.World
+	pole   *Tile
+	ball   *Tile
+	flag   *Tile
+	player *PlayerSystem
+	// ゴール演出の段階（FlagpoleXxxx）
+	phase int
+	// 現在の段階になってからの時間（秒）
+	elapsed float32
+}
+
+// Remove removes an Entity from the System
+func (*FlagpoleSystem) Remove(ecs.BasicEntity) {}
+
+// Update is ran every frame, with `dt` being the time in seconds since the last frame
+func (fs *FlagpoleSystem) Update(dt float32) {
+	switch fs.Game.State() {
+	case StatePlaying:
+		// プレイヤーがポールに触れたらゴール演出を始める
+		if overlaps(fs.player.bounds(0, 0), fs.pole.SpaceComponent.AABB()) {
+			fs.grab()
+		}
+	case StateClear:
+		fs.elapsed += dt
+		fs.updateClear(dt)
+	}
+}
+
+// grab starts the goal sequence, with points depending on the height at which the player grabbed the pole
+func (fs *FlagpoleSystem) grab() {
+	player := fs.player.playerEntity
+	// 高い位置でつかむほど高得点
+	feet := player.SpaceComponent.Position.Y + CellHeight32
+	fs.Game.Progress.AddScore(flagpoleScore(groundPositionY() + CellHeight32 - feet))
+
+	// ポールの左側につかまる（ポールの上端より上にはいかない）
+	player.SpaceComponent.Position.X = fs.pole.SpaceComponent.Position.X - CellWidth32 + ExtraSizeX
+	if top := fs.pole.SpaceComponent.Position.Y - CellHeight32/2; player.SpaceComponent.Position.Y < top {
+		player.SpaceComponent.Position.Y = top
+	}
+	player.RenderComponent.Scale.X = 1
+	player.RenderComponent.Color = color.White
+	player.RenderComponent.Hidden = false
+	player.AnimationComponent.Play(&player.RenderComponent, AnimationClimb)
+
+	// 次のコースをワールドセレクトで選べるようにする
+	fs.Game.Progress.ClearCourse()
+
+	fs.Game.SetState(StateClear)
+	fs.setPhase(FlagpoleSlide)
+}
+
+// flagpoleScore returns the points of grabbing the pole at the height (px) above the ground
+func flagpoleScore(height float32) int {
+	switch {
+	case height >= FlagpoleHeight*0.9:
+		return 5000
+	case height >= FlagpoleHeight*0.6:
+		return 2000
+	case height >= FlagpoleHeight*0.4:
+		return 800
+	case height >= FlagpoleHeight*0.2:
+		return 400
+	}
+	return 100
+}
+
+// setPhase moves the goal sequence to the phase
+func (fs *FlagpoleSystem) setPhase(phase int) {
+	fs.phase = phase
+	fs.elapsed = 0
+}
+
+// updateClear runs the phases of the goal sequence
+func (fs *FlagpoleSystem) updateClear(dt float32) {
+	player := fs.player.playerEntity
+	switch fs.phase {
+	case FlagpoleSlide:
+		// プレイヤーと旗がポールを滑り降りる
+		bottom := groundPositionY()
+		player.SpaceComponent.Position.Y += SlideSpeed * dt
+		if player.SpaceComponent.Position.Y > bottom {
+			player.SpaceComponent.Position.Y = bottom
+		}
+		flagBottom := groundPositionY() + CellHeight32 - GoalFlagHeight
+		fs.flag.SpaceComponent.Position.Y += SlideSpeed * dt
+		if fs.flag.SpaceComponent.Position.Y > flagBottom {
+			fs.flag.SpaceComponent.Position.Y = flagBottom
+		}
+		if player.SpaceComponent.Position.Y >= bottom && fs.flag.SpaceComponent.Position.Y >= flagBottom {
+			fs.setPhase(FlagpoleWait)
+		}
+	case FlagpoleWait:
+		if fs.elapsed >= FlagpoleWaitTime {
+			player.AnimationComponent.Play(&player.RenderComponent, AnimationWalk)
+			fs.setPhase(FlagpoleWalk)
+		}
+	case FlagpoleWalk:
+		// 城の入り口まで歩いたら見えなくなる
+		player.SpaceComponent.Position.X += WalkInSpeed * dt
+		player.LeftPositionX = player.SpaceComponent.Position.X + ExtraSizeX
+		player.RightPositionX = player.SpaceComponent.Position.X + CellWidth32 - ExtraSizeX
+		fs.player.updateCamera()
+		if int(player.LeftPositionX) >= (fs.Game.Level.Castle+2)*CellWidth16 {
+			fs.player.Remove(player.BasicEntity)
+			fs.setPhase(FlagpoleCount)
+		}
+	case FlagpoleCount:
+		// 残り時間をスコアに換算してから結果を表示する
+		count := TimeCountSpeed * dt
+		if count > fs.Game.time {
+			count = fs.Game.time
+		}
+		before := int(fs.Game.time)
+		fs.Game.time -= count
+		fs.Game.Progress.AddScore((before - int(fs.Game.time)) * TimeBonusScore)
+		if fs.Game.time <= 0 {
+			fs.Game.time = 0
+			fs.Game.SetState(StateGoal)
+		}
+	}
+}
+
+// New is the initialisation of the System
+func (fs *FlagpoleSystem) New(w *ecs.World) {
+	//　Worldの追加
+	fs.world = w
+	// プレイヤーの取得
+	for _, system := range fs.world.Systems() {
+		switch sys := system.(type) {
+		case *PlayerSystem:
+			fs.player = sys
+		}
+	}
+
+	// ポールはタイルの中央に立てる
+	poleX := float32(fs.Game.Level.Flagpole*CellWidth16) + (CellWidth16-FlagpoleWidth)/2
+	poleY := groundPositionY() + CellHeight32 - FlagpoleHeight
+	fs.pole = &Tile{BasicEntity: ecs.NewBasic()}
+	fs.pole.SpaceComponent = common.SpaceComponent{
+		Position: engo.Point{X: poleX, Y: poleY},
+		Width:    FlagpoleWidth,
+		Height:   FlagpoleHeight,
+	}
+	fs.pole.RenderComponent = common.RenderComponent{
+		Drawable: common.Rectangle{},
+		Color:    flagpoleColor,
+	}
+	fs.pole.RenderComponent.SetZIndex(3)
+
+	// ポールの先の玉
+	fs.ball = &Tile{BasicEntity: ecs.NewBasic()}
+	fs.ball.SpaceComponent = common.SpaceComponent{
+		Position: engo.Point{X: poleX + (FlagpoleWidth-FlagpoleBallSize)/2, Y: poleY - FlagpoleBallSize},
+		Width:    FlagpoleBallSize,
+		Height:   FlagpoleBallSize,
+	}
+	fs.ball.RenderComponent = common.RenderComponent{
+		Drawable: common.Circle{},
+		Color:    flagpoleColor,
+	}
+	fs.ball.RenderComponent.SetZIndex(3)
+
+	// 旗はポールの上端の右側に付ける（プレイヤーは左側につかまる）
+	fs.flag = &Tile{BasicEntity: ecs.NewBasic()}
+	fs.flag.SpaceComponent = common.SpaceComponent{
+		Position: engo.Point{X: poleX + FlagpoleWidth, Y: poleY + FlagpoleBallSize/2},
+		Width:    GoalFlagWidth,
+		Height:   GoalFlagHeight,
+	}
+	fs.flag.RenderComponent = common.RenderComponent{
+		Drawable: common.Triangle{TriangleType: common.TriangleRight},
+		Color:    goalFlagColor,
+	}
+	fs.flag.RenderComponent.SetZIndex(3)
+
+	// RenderSystemに追加
+	for _, system := range fs.world.Systems() {
+		switch sys := system.(type) {
+		case *common.RenderSystem:
+			for _, v := range []*Tile{fs.pole, fs.ball, fs.flag} {
+				sys.Add(&v.BasicEntity, &v.RenderComponent, &v.SpaceComponent)
+			}
+		}
+	}
+}
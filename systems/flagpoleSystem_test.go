@@ -0,0 +1,52 @@
+package systems
+
+import (
+	"testing"
+)
+
+// testFlagpole returns the FlagpoleSystem of the simulation
+func testFlagpole(sim *Simulation) *FlagpoleSystem {
+	for _, system := range sim.world.Systems() {
+		switch sys := system.(type) {
+		case *FlagpoleSystem:
+			return sys
+		}
+	}
+	return nil
+}
+
+func TestFlagpoleGrab(t *testing.T) {
+	tests := []struct {
+		name string
+		// ポールからのプレイヤーの右端の位置
+		dx float32
+		// 地面からのプレイヤーの足の高さ
+		height float32
+		// つかむか、つかんだ場合の得点
+		grab  bool
+		score int
+	}{
+		{"at the bottom", 2, 0, true, 100},
+		{"at the middle", 2, FlagpoleHeight * 0.5, true, 800},
+		{"at the top", 2, FlagpoleHeight - FlagpoleBallSize, true, 5000},
+		{"before the pole", -CellWidth16, 0, false, 0},
+		{"above the pole", 2, FlagpoleHeight + CellHeight16, false, 0},
+	}
+	for _, tt := range tests {
+		sim := newTestSimulation(1, flatLevelFile)
+		sim.Start()
+		fs := testFlagpole(sim)
+		pole := fs.pole.SpaceComponent.Position
+
+		// 足の位置（地面の上端が高さ0）
+		movePlayer(sim, pole.X+tt.dx-CellWidth32+ExtraSizeX, groundPositionY()-tt.height)
+		score := sim.Progress().Score
+		fs.Update(StepTime)
+		if got := sim.State() == StateClear; got != tt.grab {
+			t.Errorf("%s: grabbed %v, want %v", tt.name, got, tt.grab)
+		}
+		if got := sim.Progress().Score - score; got != tt.score {
+			t.Errorf("%s: score +%d, want +%d", tt.name, got, tt.score)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package systems
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/EngoEngine/engo"
+	"github.com/EngoEngine/engo/common"
+)
+
+// GameCourse is the course a scene builds and how it starts.
+// It is kept by the scene across the restarts of the world, and shared by the game and the Simulation
+// so that both build the same courses.
+type GameCourse struct {
+	// Seed : 最初のコース生成のシード
+	Seed int64
+	// LevelFile : 最初のコース（1-1）のコースファイル（空の場合はランダムなコース）
+	LevelFile string
+	// CourseSeed : 現在のコース生成のシード
+	CourseSeed int64
+	// CourseLevelFile : 現在のコースファイル
+	CourseLevelFile string
+
+	// 現在のコースの番号
+	course int
+	// タイトルを表示せずに始めるか（死亡後のやり直し、コースの変更）
+	respawn bool
+	// 死亡後にやり直す中間地点
+	checkpoint int
+	// 新しいコースのシードの生成（最初のシードから決まるため、リプレイで再現できる）
+	courses *rand.Rand
+}
+
+// NewGameCourse creates the first course of a game
+func NewGameCourse(seed int64, levelFile string) *GameCourse {
+	course := &GameCourse{Seed: seed, LevelFile: levelFile, courses: rand.New(rand.NewSource(seed))}
+	course.setCourse(0)
+	return course
+}
+
+// setCourse sets the seed and the level file of the course
+func (c *GameCourse) setCourse(course int) {
+	c.course = course
+	c.CourseSeed = CourseSeed(c.Seed, course)
+	c.CourseLevelFile = ""
+	if course == 0 {
+		c.CourseLevelFile = c.LevelFile
+	}
+}
+
+// restart chooses the course to build after the RestartMessage
+func (c *GameCourse) restart(restart RestartMessage, progress *Progress) {
+	c.respawn = restart.Respawn
+	c.checkpoint = restart.Checkpoint
+	if restart.NewCourse {
+		c.CourseSeed = c.courses.Int63()
+		c.CourseLevelFile = ""
+	} else if progress.Course != c.course {
+		// 次のコース、ワールドセレクトで選んだコース
+		c.setCourse(progress.Course)
+	}
+}
+
+// NewGameState creates the state of the course, continuing the progress.
+// After a death or a change of course the game starts without the title, from the checkpoint passed
+func (c *GameCourse) NewGameState(progress *Progress) *GameState {
+	game := NewGameState(progress)
+	if c.respawn {
+		game.SetState(StatePlaying)
+		game.SetCheckpoint(c.checkpoint)
+	}
+	return game
+}
+
+// LoadGameFiles loads the images of the game and the level file of the course (if any)
+func LoadGameFiles(levelFile string) {
+	engo.Files.Load(playerFile, enermyFile, itemFile, tileFile, castleFile)
+	if levelFile != "" {
+		if err := engo.Files.Load(levelFile); err != nil {
+			fmt.Println("Unable to load level: " + levelFile + "：" + err.Error())
+		}
+	}
+}
+
+// AddGameSystems adds the systems of the game to the world, in the order they are updated,
+// and returns the PlayerSystem
+func AddGameSystems(world *FixedStepWorld, game *GameState, source InputSource, seed int64, levelFile string) *PlayerSystem {
+	player := &PlayerSystem{Game: game}
+
+	world.AddSystem(&common.RenderSystem{})
+	world.AddSystem(&AnimationSystem{Game: game})
+	world.AddSystem(&InputSystem{Source: source})
+	world.AddSystem(&GameStateSystem{Game: game})
+	world.AddSystem(&TileSystem{Seed: seed, LevelFile: levelFile, Game: game})
+	world.AddSystem(&CollisionSystem{Game: game})
+	world.AddSystem(player)
+	world.AddSystem(&EnermySystem{Game: game})
+	world.AddSystem(&CheckpointSystem{Game: game})
+	world.AddSystem(&FlagpoleSystem{Game: game})
+	world.AddSystem(&BlockSystem{Game: game})
+	world.AddSystem(&FireballSystem{Game: game})
+	world.AddSystem(&HUDTextSystem{Game: game})
+	return player
+}
+
+// ListenRestart rebuilds the world of the scene on a RestartMessage,
+// with the course chosen by the message
+func ListenRestart(world *FixedStepWorld, scene engo.Scene, course *GameCourse, progress *Progress) {
+	engo.Mailbox.Listen("RestartMessage", func(msg engo.Message) {
+		restart, ok := msg.(RestartMessage)
+		if !ok {
+			return
+		}
+		course.restart(restart, progress)
+		world.Stop()
+		engo.SetScene(scene, true)
+	})
+}
@@ -0,0 +1,93 @@
+package systems
+
+// GameState is the level and the state of a game.
+// It is owned by the scene and injected into the systems of its world,
+// so that restarting a course or running two worlds never shares stale data.
+type GameState struct {
+	// Level : コース（TileSystemが読み込む）
+	Level *Level
+	// CollisionMap : コースのコリジョンマップ（TileSystemが作成する）
+	CollisionMap *CollisionMap
+	// Progress : スコア、コイン、残り人数（コースをやり直しても引き継ぐ）
+	Progress *Progress
+
+	// 現在の状態
+	state int
+	// 現在の状態になってからの時間
+	elapsed float32
+	// メニューで選択中の項目の位置
+	menuIndex int
+	// 残り時間（秒）
+	time float32
+	// 通過した中間地点のタイル位置（0の場合は通過していない）
+	checkpoint int
+}
+
+// NewGameState creates the state of a course at the title, continuing the progress.
+// The world select of the title starts on the current course
+func NewGameState(progress *Progress) *GameState {
+	return &GameState{state: StateTitle, Progress: progress, time: TimeLimit, menuIndex: progress.Course}
+}
+
+// Time returns the time left to reach the goal in seconds
+func (g *GameState) Time() float32 {
+	return g.time
+}
+
+// Checkpoint returns the tile position of the last checkpoint passed (0 if none)
+func (g *GameState) Checkpoint() int {
+	return g.checkpoint
+}
+
+// SetCheckpoint records the checkpoint passed, where the player respawns after a death
+func (g *GameState) SetCheckpoint(x int) {
+	g.checkpoint = x
+}
+
+// State returns the current state of the game
+func (g *GameState) State() int {
+	return g.state
+}
+
+// Menu returns the items of the menu shown in the current state (nil if there is no menu)
+func (g *GameState) Menu() []int {
+	switch g.state {
+	case StateTitle:
+		// ワールドセレクト（選べるコースのみ）
+		menu := make([]int, g.Progress.Unlocked)
+		for i := range menu {
+			menu[i] = MenuCourse + i
+		}
+		return menu
+	case StatePaused:
+		return pauseMenu
+	case StateGoal:
+		if g.Progress.NextCourse() {
+			return goalMenu
+		}
+		return endMenu
+	case StateGameOver:
+		return endMenu
+	}
+	return nil
+}
+
+// MenuItem returns the item selected in the menu (MenuNone if there is no menu)
+func (g *GameState) MenuItem() int {
+	menu := g.Menu()
+	if len(menu) == 0 {
+		return MenuNone
+	}
+	// 項目の数より後を指している場合は最後の項目（選べないコースのワールドセレクトなど）
+	if g.menuIndex >= len(menu) {
+		return menu[len(menu)-1]
+	}
+	return menu[g.menuIndex]
+}
+
+// SetState changes the state of the game
+func (g *GameState) SetState(state int) {
+	g.state = state
+	g.elapsed = 0
+	g.menuIndex = 0
+}
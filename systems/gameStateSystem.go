@@ -0,0 +1,192 @@
+package systems
+
+import (
+	"github.com/EngoEngine/ecs"
+	"github.com/EngoEngine/engo"
+)
+
+const (
+	// StateTitle : タイトル
+	StateTitle = 0
+	// StatePlaying : プレイ中
+	StatePlaying = 1
+	// StatePaused : ポーズ中
+	StatePaused = 2
+	// StateDying : 死亡中
+	StateDying = 3
+	// StateGoal : ゴール
+	StateGoal = 4
+	// StateGameOver : ゲームオーバー
+	StateGameOver = 5
+	// StateClear : ゴール演出中（ポールをつかんでから結果を表示するまで）
+	StateClear = 6
+)
+
+const (
+	// MenuNone : メニューなし
+	MenuNone = -1
+	// MenuResume : 再開
+	MenuResume = 0
+	// MenuRetry : 同じコースをやり直す
+	MenuRetry = 1
+	// MenuNewCourse : 新しいランダムなコースで始める
+	MenuNewCourse = 2
+	// MenuQuit : 終了
+	MenuQuit = 3
+	// MenuNextCourse : 次のコースに進む
+	MenuNextCourse = 4
+	// MenuCourse : ワールドセレクトで選んだコースで始める（MenuCourse+コース番号）
+	MenuCourse = 5
+)
+
+// pauseMenu : ポーズメニューの項目
+var pauseMenu = []int{MenuResume, MenuRetry, MenuNewCourse, MenuQuit}
+
+// endMenu : ゴール（最後のコース）、ゲームオーバー時のメニューの項目
+var endMenu = []int{MenuRetry, MenuNewCourse}
+
+// goalMenu : 次のコースがある場合のゴール時のメニューの項目
+var goalMenu = []int{MenuNextCourse, MenuRetry, MenuNewCourse}
+
+// RestartMessage is dispatched to ask the scene to rebuild the world,
+// with the same course, a new random course, or the course of Progress.Course if it changed
+type RestartMessage struct {
+	// NewCourse : 新しいランダムなコースにするか
+	NewCourse bool
+	// Respawn : タイトルを表示せずにすぐ始めるか（死亡後のやり直し、コースの変更）
+	Respawn bool
+	// Checkpoint : 死亡後にやり直す中間地点のタイル位置（0の場合はスタートから）
+	Checkpoint int
+}
+
+// Type implements the engo.Message interface
+func (RestartMessage) Type() string { return "RestartMessage" }
+
+const (
+	// GameStateSystemPriority : GameStateSystemの優先度（入力の次に状態を更新する）
+	GameStateSystemPriority = 5
+	// DyingTime : 死亡してからやり直し（ゲームオーバー）になるまでの時間（秒）
+	DyingTime = 3
+	// TimeLimit : コースの制限時間（秒）
+	TimeLimit = 300
+)
+
+// GameStateSystem is the state machine of the game flow
+// (Title, Playing, Paused, Dying, Clear, Goal, GameOver).
+// The other systems consult the GameState instead of package-level flags.
+type GameStateSystem struct {
+	// Game : ゲームの状態
+	Game *GameState
+
+	world *ecs.World
+	input *InputSystem
+}
+
+// Priority runs the GameStateSystem after the InputSystem, before the other systems
+func (*GameStateSystem) Priority() int { return GameStateSystemPriority }
+
+// Remove removes an Entity from the System
+func (*GameStateSystem) Remove(ecs.BasicEntity) {}
+
+// Update is ran every frame, with `dt` being the time in seconds since the last frame
+func (gs *GameStateSystem) Update(dt float32) {
+	game := gs.Game
+	game.elapsed += dt
+
+	switch game.state {
+	case StateTitle:
+		// ワールドセレクト
+		gs.updateMenu()
+	case StatePlaying:
+		if gs.input.JustPressed(ButtonPause) {
+			game.SetState(StatePaused)
+			return
+		}
+		// 時間切れで死亡
+		game.time -= dt
+		if game.time <= 0 {
+			game.time = 0
+			for _, system := range gs.world.Systems() {
+				switch sys := system.(type) {
+				case *PlayerSystem:
+					sys.PlayerDie()
+				}
+			}
+		}
+	case StatePaused:
+		// ポーズメニュー
+		if gs.input.JustPressed(ButtonPause) {
+			game.SetState(StatePlaying)
+		} else {
+			gs.updateMenu()
+		}
+	case StateDying:
+		if game.elapsed >= DyingTime {
+			if game.Progress.Lives > 0 {
+				// 残り人数があればコースをやり直す
+				engo.Mailbox.Dispatch(RestartMessage{NewCourse: false, Respawn: true, Checkpoint: game.checkpoint})
+			} else {
+				game.SetState(StateGameOver)
+			}
+		}
+	case StateGoal, StateGameOver:
+		// リトライ、新しいコース
+		gs.updateMenu()
+	}
+}
+
+// updateMenu moves the selection of the menu and runs the selected item
+func (gs *GameStateSystem) updateMenu() {
+	game := gs.Game
+	menu := game.Menu()
+	switch {
+	case gs.input.JustPressed(ButtonMenuUp):
+		game.menuIndex = (game.menuIndex + len(menu) - 1) % len(menu)
+	case gs.input.JustPressed(ButtonMenuDown):
+		game.menuIndex = (game.menuIndex + 1) % len(menu)
+	case gs.input.JustPressed(ButtonEnter):
+		// ゲームオーバーからは新しいゲームを始める
+		if game.state == StateGameOver {
+			game.Progress.Reset()
+		}
+		switch game.MenuItem() {
+		case MenuResume:
+			game.SetState(StatePlaying)
+		case MenuRetry:
+			engo.Mailbox.Dispatch(RestartMessage{NewCourse: false})
+		case MenuNewCourse:
+			engo.Mailbox.Dispatch(RestartMessage{NewCourse: true})
+		case MenuQuit:
+			engo.Exit()
+		case MenuNextCourse:
+			game.Progress.Course++
+			engo.Mailbox.Dispatch(RestartMessage{Respawn: true})
+		default:
+			gs.selectCourse(game.MenuItem() - MenuCourse)
+		}
+	}
+}
+
+// selectCourse starts the course chosen in the world select,
+// rebuilding the world if it is not the course already built
+func (gs *GameStateSystem) selectCourse(course int) {
+	game := gs.Game
+	if course == game.Progress.Course {
+		game.SetState(StatePlaying)
+		return
+	}
+	game.Progress.Course = course
+	engo.Mailbox.Dispatch(RestartMessage{Respawn: true})
+}
+
+// New is the initialisation of the System
+func (gs *GameStateSystem) New(w *ecs.World) {
+	gs.world = w
+	// 入力の取得
+	for _, system := range gs.world.Systems() {
+		switch sys := system.(type) {
+		case *InputSystem:
+			gs.input = sys
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package systems
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPauseFreezesGame(t *testing.T) {
+	sim := newTestSimulation(1, flatLevelFile)
+	sim.Start()
+	sim.Run(10, ButtonMoveRight)
+
+	// ポーズ中は右を押しても動かない
+	sim.Run(1, ButtonPause)
+	if sim.State() != StatePaused {
+		t.Fatalf("state %d after pressing pause, want StatePaused", sim.State())
+	}
+	paused := sim.PlayerPosition()
+	sim.Run(60, ButtonMoveRight)
+	if p := sim.PlayerPosition(); p != paused {
+		t.Errorf("player moved from %v to %v while paused", paused, p)
+	}
+	// ジャンプ中にポーズしても落ちてこない
+	sim.Run(1, ButtonPause)
+	sim.Run(5, ButtonJump)
+	sim.Run(1, ButtonPause)
+	jumping := sim.PlayerPosition()
+	if jumping.Y >= paused.Y {
+		t.Fatalf("player at %v, want in the air above %v", jumping, paused)
+	}
+	sim.Step(60)
+	if p := sim.PlayerPosition(); p != jumping {
+		t.Errorf("player moved from %v to %v while paused in a jump", jumping, p)
+	}
+
+	// ポーズを解除すると再び動く
+	sim.Run(1, ButtonPause)
+	if sim.State() != StatePlaying {
+		t.Fatalf("state %d after pressing pause again, want StatePlaying", sim.State())
+	}
+	sim.Run(30, ButtonMoveRight)
+	if p := sim.PlayerPosition(); p.X <= jumping.X {
+		t.Errorf("player at %v after resuming, want right of %v", p, jumping)
+	}
+}
+
+func TestPauseMenu(t *testing.T) {
+	sim := newTestSimulation(1, flatLevelFile)
+	sim.Start()
+	start := sim.PlayerPosition()
+	sim.Run(30, ButtonMoveRight)
+
+	// メニューは上下でループする
+	sim.Run(1, ButtonPause)
+	tests := []struct {
+		button string
+		want   int
+	}{
+		{ButtonMenuDown, MenuRetry},
+		{ButtonMenuDown, MenuNewCourse},
+		{ButtonMenuDown, MenuQuit},
+		{ButtonMenuDown, MenuResume},
+		{ButtonMenuUp, MenuQuit},
+		{ButtonMenuUp, MenuNewCourse},
+		{ButtonMenuUp, MenuRetry},
+	}
+	for _, tt := range tests {
+		sim.Run(1, tt.button)
+		sim.Step(1)
+		if got := sim.game.MenuItem(); got != tt.want {
+			t.Errorf("after %s: menu item %d, want %d", tt.button, got, tt.want)
+		}
+	}
+
+	// やり直すとスタート地点でタイトルに戻る
+	sim.Run(1, ButtonEnter)
+	if sim.State() != StateTitle {
+		t.Errorf("state %d after restarting, want StateTitle", sim.State())
+	}
+	if p := sim.PlayerPosition(); p != start {
+		t.Errorf("player at %v after restarting, want %v", p, start)
+	}
+
+	// 再開を選ぶとポーズが解除される
+	sim.Start()
+	sim.Run(1, ButtonPause)
+	sim.Run(1, ButtonEnter)
+	if sim.State() != StatePlaying {
+		t.Errorf("state %d after resuming from the menu, want StatePlaying", sim.State())
+	}
+}
+
+func TestDyingThenGameOver(t *testing.T) {
+	sim := newTestSimulation(pitSeed, "")
+	sim.Start()
+	start := sim.PlayerPosition()
+
+	// 残り人数があればタイトルを表示せずにコースをやり直す
+	runUntil(sim, 600, sim.GameOver, ButtonMoveRight)
+	if sim.State() != StateDying {
+		t.Fatalf("state %d after falling, want StateDying", sim.State())
+	}
+	if got := sim.Progress().Lives; got != StartLives-1 {
+		t.Errorf("lives %d after dying, want %d", got, StartLives-1)
+	}
+	sim.Step(DyingTime*60 + 1)
+	if sim.State() != StatePlaying || sim.PlayerPosition() != start {
+		t.Errorf("state %d, player at %v after dying with lives left, want StatePlaying at %v", sim.State(), sim.PlayerPosition(), start)
+	}
+	if got := sim.Progress().Lives; got != StartLives-1 {
+		t.Errorf("lives %d after respawning, want %d", got, StartLives-1)
+	}
+
+	// 最後の1人で死亡するとゲームオーバー
+	sim.Progress().Lives = 1
+	runUntil(sim, 600, sim.GameOver, ButtonMoveRight)
+	sim.Step(DyingTime*60 + 1)
+	if sim.State() != StateGameOver || sim.Progress().Lives != 0 {
+		t.Errorf("state %d lives %d after the last life, want StateGameOver 0", sim.State(), sim.Progress().Lives)
+	}
+}
+
+func TestRetryAndNewCourse(t *testing.T) {
+	sim := newTestSimulation(pitSeed, "")
+	sim.Start()
+	start := sim.PlayerPosition()
+	level := sim.Level()
+
+	// ゲームオーバーの後、同じコースをやり直す
+	sim.Progress().Lives = 1
+	runUntil(sim, 600, sim.GameOver, ButtonMoveRight)
+	sim.Step(DyingTime*60 + 1)
+	if got := sim.game.MenuItem(); got != MenuRetry {
+		t.Fatalf("game over menu item %d, want MenuRetry", got)
+	}
+	sim.Run(1, ButtonEnter)
+	if sim.State() != StateTitle || sim.PlayerPosition() != start {
+		t.Errorf("state %d, player at %v after retrying, want StateTitle at %v", sim.State(), sim.PlayerPosition(), start)
+	}
+	if !reflect.DeepEqual(sim.Level(), level) {
+		t.Errorf("retried another course")
+	}
+
+	// 新しいコースで始める
+	sim.Start()
+	sim.Progress().Lives = 1
+	runUntil(sim, 600, sim.GameOver, ButtonMoveRight)
+	sim.Step(DyingTime*60 + 1)
+	sim.Run(1, ButtonMenuDown)
+	sim.Step(1)
+	if got := sim.game.MenuItem(); got != MenuNewCourse {
+		t.Fatalf("game over menu item %d, want MenuNewCourse", got)
+	}
+	sim.Run(1, ButtonEnter)
+	if sim.State() != StateTitle {
+		t.Errorf("state %d after choosing a new course, want StateTitle", sim.State())
+	}
+	if reflect.DeepEqual(sim.Level(), level) {
+		t.Errorf("the new course is the same as the previous one")
+	}
+}
+
+func TestMenuItemOutOfRange(t *testing.T) {
+	// 選べるコースより後のコースを遊んでいた場合（ゲームオーバーの後など）
+	game := NewGameState(&Progress{Course: 3, Unlocked: 1})
+	if got := game.MenuItem(); got != MenuCourse {
+		t.Errorf("title menu item %d, want %d", got, MenuCourse)
+	}
+	game.SetState(StatePlaying)
+	if got := game.MenuItem(); got != MenuNone {
+		t.Errorf("menu item %d while playing, want MenuNone", got)
+	}
+}
+
+func TestGoalNextCourse(t *testing.T) {
+	sim := newTestSimulation(1, flatLevelFile)
+	sim.Start()
+
+	runUntil(sim, 1800, func() bool { return sim.State() == StateGoal }, ButtonMoveRight)
+	if sim.State() != StateGoal {
+		t.Fatalf("state %d, want StateGoal", sim.State())
+	}
+	// クリアすると次のコースが選べるようになる
+	if sim.Progress().Unlocked != 2 {
+		t.Errorf("unlocked %d after the goal, want 2", sim.Progress().Unlocked)
+	}
+	if got := sim.game.MenuItem(); got != MenuNextCourse {
+		t.Fatalf("goal menu item %d, want MenuNextCourse", got)
+	}
+
+	// 次のコース（地下）がタイトルを表示せずに始まる
+	sim.Run(1, ButtonEnter)
+	sim.Step(1)
+	if sim.Progress().Course != 1 || sim.Course().Name != "1-2" {
+		t.Errorf("course %d %q, want 1 \"1-2\"", sim.Progress().Course, sim.Course().Name)
+	}
+	if level := sim.Level(); level.Name != "1-2" || level.Theme != ThemeUnderground {
+		t.Errorf("level %q theme %d, want \"1-2\" ThemeUnderground", level.Name, level.Theme)
+	}
+	if sim.State() != StatePlaying {
+		t.Errorf("state %d on the next course, want StatePlaying", sim.State())
+	}
+}
+
+func TestWorldSelect(t *testing.T) {
+	sim := newTestSimulation(1, flatLevelFile)
+	// 最初は1-1しか選べない
+	sim.Run(1, ButtonMenuDown)
+	sim.Step(1)
+	if got := sim.game.MenuItem(); got != MenuCourse {
+		t.Errorf("world select item %d with one course, want %d", got, MenuCourse)
+	}
+
+	// 1-3までクリアした後のタイトル
+	sim.Progress().Unlocked = 3
+	if got := len(sim.game.Menu()); got != 3 {
+		t.Fatalf("%d courses in the world select, want 3", got)
+	}
+	sim.Run(1, ButtonMenuDown)
+	sim.Step(1)
+	sim.Run(1, ButtonMenuDown)
+	if got := menuText(sim.game.MenuItem()); got != "WORLD 1-3" {
+		t.Errorf("world select %q, want \"WORLD 1-3\"", got)
+	}
+	// 選んだコースがタイトルを表示せずに始まる
+	sim.Run(1, ButtonEnter)
+	sim.Step(1)
+	if level := sim.Level(); sim.Progress().Course != 2 || level.Name != "1-3" {
+		t.Errorf("course %d level %q, want 2 \"1-3\"", sim.Progress().Course, level.Name)
+	}
+	if sim.State() != StatePlaying {
+		t.Errorf("state %d on the selected course, want StatePlaying", sim.State())
+	}
+}
@@ -1,7 +1,9 @@
 package systems
 
 import (
+	"fmt"
 	"image/color"
+	"math"
 
 	"github.com/EngoEngine/ecs"
 	"github.com/EngoEngine/engo"
@@ -17,8 +19,31 @@ const (
 	TextGOAL = 2
 	// TextEND : 終了
 	TextEND = 3
+	// TextPAUSE : ポーズメニュー
+	TextPAUSE = 4
 )
 
+// StatusTextSize : 画面上部のステータスの文字サイズ
+const StatusTextSize = 16
+
+// menuTexts : メニューの項目のテキスト
+var menuTexts = map[int]string{
+	MenuResume:     "RESUME",
+	MenuRetry:      "RETRY COURSE",
+	MenuNewCourse:  "NEW COURSE",
+	MenuQuit:       "QUIT",
+	MenuNextCourse: "NEXT COURSE",
+}
+
+// menuText returns the text of an item of the menu
+func menuText(item int) string {
+	// ワールドセレクトのコース
+	if item >= MenuCourse {
+		return "WORLD " + Courses[item-MenuCourse].Name
+	}
+	return menuTexts[item]
+}
+
 // Text is an entity containing text printed to the screen
 type Text struct {
 	ecs.BasicEntity
@@ -30,36 +55,44 @@ type Text struct {
 
 // HUDTextSystem prints the text to our HUD based on the current state of the game
 type HUDTextSystem struct {
+	// Game : ゲームの状態
+	Game *GameState
+
 	world      *ecs.World
 	TextEntity *Text
+	// StatusEntity : 画面上部のステータス（スコア、コイン、ワールド、時間、残り人数）
+	StatusEntity *Text
+	// 表示中のメニューの選択項目
+	menuItem int
+	// ステータスのフォント（テキストを変更するたびに作成しないよう使い回す）
+	statusFont *common.Font
+	// 表示中のステータス
+	status string
 }
 
 // Update is
 func (h *HUDTextSystem) Update(dt float32) {
-	if engo.Input.Button("Enter").Down() {
-		switch h.TextEntity.textNo {
-		case TextTITLE:
-			for _, system := range h.world.Systems() {
-				switch sys := system.(type) {
-				case *PlayerSystem:
-					sys.playerEntity.ifStart = true
-				}
-			}
-			h.Remove(h.TextEntity.BasicEntity)
-			h.TextEntity.textNo = TextNONE
-			ifGameOver = false
-
-		case TextGOAL, TextEND:
-			for _, system := range h.world.Systems() {
-				switch sys := system.(type) {
-				case *PlayerSystem:
-					// リトライ
-					sys.PlayerInit(sys.playerEntity)
-				}
-			}
-			h.TextInit(h.TextEntity, TextTITLE)
-		}
+	h.updateStatus()
+
+	// ゲームの状態に合わせたテキスト
+	textNo := TextNONE
+	switch h.Game.State() {
+	case StateTitle:
+		textNo = TextTITLE
+	case StatePaused:
+		textNo = TextPAUSE
+	case StateGoal:
+		textNo = TextGOAL
+	case StateGameOver:
+		textNo = TextEND
 	}
+	// 表示中のテキストから変化がなければ何もしない
+	if textNo == h.TextEntity.textNo && h.menuItem == h.Game.MenuItem() {
+		return
+	}
+	h.Remove(h.TextEntity.BasicEntity)
+	h.menuItem = h.Game.MenuItem()
+	h.TextInit(h.TextEntity, textNo)
 }
 
 // Remove takes an Entity out of the RenderSystem.
@@ -79,6 +112,53 @@ func (h *HUDTextSystem) New(w *ecs.World) {
 	text := &Text{BasicEntity: ecs.NewBasic()}
 	// 初期化
 	h.TextInit(text, TextTITLE)
+	h.StatusInit()
+}
+
+// StatusInit creates the status at the top of the screen
+func (h *HUDTextSystem) StatusInit() {
+	status := &Text{BasicEntity: ecs.NewBasic()}
+	status.SpaceComponent = common.SpaceComponent{
+		Position: engo.Point{X: CellWidth16, Y: CellHeight16 / 2},
+	}
+	h.statusFont = &common.Font{
+		URL:  "go.ttf",
+		FG:   color.White,
+		Size: StatusTextSize,
+	}
+	h.statusFont.CreatePreloaded()
+	status.RenderComponent.Drawable = common.Text{
+		Font:        h.statusFont,
+		LineSpacing: 0.2,
+	}
+	status.SetShader(common.TextHUDShader)
+	status.RenderComponent.SetZIndex(10)
+
+	h.StatusEntity = status
+	h.updateStatus()
+	for _, system := range h.world.Systems() {
+		switch sys := system.(type) {
+		case *common.RenderSystem:
+			sys.Add(&status.BasicEntity, &status.RenderComponent, &status.SpaceComponent)
+		}
+	}
+}
+
+// updateStatus prints the score, the coins, the world, the time left and the lives
+func (h *HUDTextSystem) updateStatus() {
+	progress := h.Game.Progress
+	status := fmt.Sprintf("MARIO      COINS      WORLD      TIME      LIVES\n%06d      x%02d         %-5s        %03d        x%d",
+		progress.Score, progress.Coins, h.Game.Level.Name, int(math.Ceil(float64(h.Game.Time()))), progress.Lives)
+	// 変化がなければ何もしない
+	if status == h.status {
+		return
+	}
+	h.status = status
+	h.StatusEntity.RenderComponent.Drawable = common.Text{
+		Font:        h.statusFont,
+		Text:        status,
+		LineSpacing: 0.2,
+	}
 }
 
 // TextInit initializes the value of TextEntity
@@ -101,6 +181,25 @@ func (h *HUDTextSystem) TextInit(text *Text, textNo int) {
 		textDisplay = "             GOAL!!"
 	case TextEND:
 		textDisplay = "          GAME OVER"
+	case TextPAUSE:
+		textDisplay = "         PAUSE"
+	}
+	// メニュー（ワールドセレクト、ポーズ、ゴール、ゲームオーバー）
+	if menu := h.Game.Menu(); len(menu) > 0 {
+		size = 24
+		textDisplay = "         " + textDisplay + "\n"
+		for _, item := range menu {
+			// 選択中の項目に印をつける
+			if item == h.menuItem {
+				textDisplay += "\n              > " + menuText(item)
+			} else {
+				textDisplay += "\n                 " + menuText(item)
+			}
+		}
+	}
+	// テキストなし
+	if textNo == TextNONE {
+		return
 	}
 
 	// SpaceComponent
@@ -0,0 +1,144 @@
+package systems
+
+import (
+	"github.com/EngoEngine/ecs"
+	"github.com/EngoEngine/engo"
+)
+
+const (
+	// ButtonMoveRight : 右移動
+	ButtonMoveRight = "MoveRight"
+	// ButtonMoveLeft : 左移動
+	ButtonMoveLeft = "MoveLeft"
+	// ButtonJump : ジャンプ
+	ButtonJump = "Jump"
+	// ButtonEnter : 決定
+	ButtonEnter = "Enter"
+	// ButtonPause : ポーズ
+	ButtonPause = "Pause"
+	// ButtonMenuUp : メニューの上の項目
+	ButtonMenuUp = "MenuUp"
+	// ButtonMenuDown : メニューの下の項目
+	ButtonMenuDown = "MenuDown"
+	// ButtonFire : ファイアボールを投げる
+	ButtonFire = "Fire"
+)
+
+// InputSystemPriority : InputSystemの優先度（他のSystemより先に入力を読み取る）
+const InputSystemPriority = 10
+
+// Buttons are the buttons read by the InputSystem.
+// New buttons must be appended, as replays store the buttons in this order.
+var Buttons = []string{ButtonMoveRight, ButtonMoveLeft, ButtonJump, ButtonEnter, ButtonPause, ButtonMenuUp, ButtonMenuDown, ButtonFire}
+
+// InputSource is a source of the button states consumed by the systems
+// (keyboard, gamepad, replay file, scripted test driver, ...)
+type InputSource interface {
+	// Update is called at every step, before reading the buttons
+	Update()
+	// ButtonDown returns whether the button is pressed at the current step
+	ButtonDown(name string) bool
+}
+
+// KeyboardInput is the InputSource reading the buttons registered in engo.Input
+type KeyboardInput struct{}
+
+// Update does nothing, engo updates the keys every frame
+func (KeyboardInput) Update() {}
+
+// ButtonDown returns whether one of the keys of the button is pressed
+func (KeyboardInput) ButtonDown(name string) bool {
+	button := engo.Input.Button(name)
+	return button.JustPressed() || button.Down()
+}
+
+// ScriptedInput is an InputSource whose buttons are set by a program (tests, AI agents, ...)
+type ScriptedInput struct {
+	// 押されているボタン
+	buttons map[string]bool
+}
+
+// Hold sets the pressed buttons. The other buttons are released
+func (s *ScriptedInput) Hold(buttons ...string) {
+	s.buttons = make(map[string]bool)
+	for _, name := range buttons {
+		s.buttons[name] = true
+	}
+}
+
+// Update does nothing, the buttons stay held until the next Hold
+func (s *ScriptedInput) Update() {}
+
+// ButtonDown returns whether the button is held
+func (s *ScriptedInput) ButtonDown(name string) bool {
+	return s.buttons[name]
+}
+
+// buttonState tracks a button at every step of the FixedStepWorld,
+// so that a press is neither lost nor repeated when a frame runs zero or several steps
+type buttonState struct {
+	// 押されているか
+	pressed bool
+	// 前のステップで押されていたか
+	lastPressed bool
+}
+
+// update sets the state of the button for the current step
+func (b *buttonState) update(pressed bool) {
+	b.lastPressed = b.pressed
+	b.pressed = pressed
+}
+
+// InputSystem reads the buttons from its InputSource at every step, before the other systems.
+// The systems consume the buttons through the InputSystem instead of reading engo.Input.
+type InputSystem struct {
+	// Source : 入力元（nilの場合はキーボード）
+	Source InputSource
+	// ボタンの状態
+	buttons map[string]*buttonState
+}
+
+// Priority runs the InputSystem before the other systems
+func (*InputSystem) Priority() int { return InputSystemPriority }
+
+// Remove removes an Entity from the System
+func (*InputSystem) Remove(ecs.BasicEntity) {}
+
+// Update is ran every frame, with `dt` being the time in seconds since the last frame
+func (is *InputSystem) Update(dt float32) {
+	is.Source.Update()
+	for _, name := range Buttons {
+		state, ok := is.buttons[name]
+		if !ok {
+			state = &buttonState{}
+			is.buttons[name] = state
+		}
+		state.update(is.Source.ButtonDown(name))
+	}
+}
+
+// New is the initialisation of the System
+func (is *InputSystem) New(w *ecs.World) {
+	if is.Source == nil {
+		is.Source = KeyboardInput{}
+	}
+	is.buttons = make(map[string]*buttonState)
+}
+
+// Down returns whether the button is pressed
+func (is *InputSystem) Down(name string) bool {
+	state, ok := is.buttons[name]
+	return ok && state.pressed
+}
+
+// JustPressed returns whether the button was pressed at this step
+func (is *InputSystem) JustPressed(name string) bool {
+	state, ok := is.buttons[name]
+	return ok && state.pressed && !state.lastPressed
+}
+
+// JustReleased returns whether the button was released at this step
+func (is *InputSystem) JustReleased(name string) bool {
+	state, ok := is.buttons[name]
+	return ok && !state.pressed && state.lastPressed
+}
@@ -0,0 +1,59 @@
+package systems
+
+import (
+	"testing"
+
+	"github.com/EngoEngine/ecs"
+)
+
+func TestInputSystemSteps(t *testing.T) {
+	scripted := &ScriptedInput{}
+	is := &InputSystem{Source: scripted}
+	is.New(&ecs.World{})
+
+	tests := []struct {
+		name string
+		// 押しているか
+		hold                            bool
+		down, justPressed, justReleased bool
+	}{
+		{"not pressed", false, false, false, false},
+		{"pressed", true, true, true, false},
+		{"held", true, true, false, false},
+		{"released", false, false, false, true},
+		{"still released", false, false, false, false},
+		{"pressed again", true, true, true, false},
+	}
+	for _, tt := range tests {
+		if tt.hold {
+			scripted.Hold(ButtonJump)
+		} else {
+			scripted.Hold()
+		}
+		is.Update(StepTime)
+		if got := is.Down(ButtonJump); got != tt.down {
+			t.Errorf("%s: down %v, want %v", tt.name, got, tt.down)
+		}
+		if got := is.JustPressed(ButtonJump); got != tt.justPressed {
+			t.Errorf("%s: just pressed %v, want %v", tt.name, got, tt.justPressed)
+		}
+		if got := is.JustReleased(ButtonJump); got != tt.justReleased {
+			t.Errorf("%s: just released %v, want %v", tt.name, got, tt.justReleased)
+		}
+		// 他のボタンには影響しない
+		if is.Down(ButtonMoveRight) || is.JustPressed(ButtonMoveRight) {
+			t.Errorf("%s: MoveRight pressed", tt.name)
+		}
+	}
+}
+
+func TestInputSystemBeforeFirstStep(t *testing.T) {
+	scripted := &ScriptedInput{}
+	scripted.Hold(ButtonJump)
+	is := &InputSystem{Source: scripted}
+	is.New(&ecs.World{})
+	// 最初のステップまでは何も押されていない
+	if is.Down(ButtonJump) || is.JustPressed(ButtonJump) || is.JustReleased(ButtonJump) {
+		t.Errorf("jump pressed before the first step")
+	}
+}
@@ -0,0 +1,458 @@
+package systems
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"strconv"
+
+	"github.com/EngoEngine/engo"
+	"github.com/EngoEngine/engo/common"
+)
+
+const (
+	// CloudTileNum : 雲のTile数
+	CloudTileNum = 3
+	// MountIntervalTileNum : 山の間隔
+	MountIntervalTileNum = 20
+	// PipeIntervalTileNum : 土管の間隔
+	PipeIntervalTileNum = 30
+	// StartTileNum : スタート付近のタイル数
+	StartTileNum = 10
+	// WalkerIntervalTileNum : 歩く敵キャラの最小の間隔
+	WalkerIntervalTileNum = 8
+	// BlockIntervalTileNum : ブロックの最小の間隔
+	BlockIntervalTileNum = 6
+	// MaxBlockTileNum : 並べるブロックの最大数
+	MaxBlockTileNum = 4
+	// BlockHeight : ブロックの地面からの高さ（タイル数）
+	BlockHeight = 4
+	// DefaultLevelName : コース名が指定されていない場合のコース名
+	DefaultLevelName = "1-1"
+)
+
+const (
+	// TMXLayerGround : 地面のタイルレイヤー名
+	TMXLayerGround = "ground"
+	// TMXLayerBackground : 背景のタイルレイヤー名
+	TMXLayerBackground = "background"
+	// TMXLayerPipes : 土管のタイルレイヤー名
+	TMXLayerPipes = "pipes"
+	// TMXObjectEnemy : 敵キャラの出現位置のオブジェクトタイプ
+	TMXObjectEnemy = "enemy"
+	// TMXObjectGoal : ゴールのオブジェクトタイプ
+	TMXObjectGoal = "goal"
+	// TMXObjectStart : スタートのオブジェクトタイプ
+	TMXObjectStart = "start"
+	// TMXObjectBrick : レンガブロックのオブジェクトタイプ
+	TMXObjectBrick = "brick"
+	// TMXObjectQuestion : ？ブロックのオブジェクトタイプ
+	TMXObjectQuestion = "question"
+	// TMXObjectCheckpoint : 中間地点のオブジェクトタイプ
+	TMXObjectCheckpoint = "checkpoint"
+	// TMXObjectFlagpole : ゴールのポールのオブジェクトタイプ
+	TMXObjectFlagpole = "flagpole"
+)
+
+// Level is the layout of a course
+type Level struct {
+	// Name : コース名（HUDに表示するワールド名）
+	Name string `json:"name"`
+	// Theme : テーマ（ThemeOverworld, ThemeUnderground, ThemeCastle）
+	Theme int `json:"theme"`
+	// Width : コースのタイル数
+	Width int `json:"width"`
+	// Pits : 落とし穴のタイル位置
+	Pits []int `json:"pits"`
+	// Mountains : 山のタイル位置（左端）
+	Mountains []int `json:"mountains"`
+	// Pipes : 土管のタイル位置（左端）
+	Pipes []int `json:"pipes"`
+	// Clouds : 雲
+	Clouds []Cloud `json:"clouds"`
+	// Enemies : 敵キャラの出現位置
+	Enemies []Spawn `json:"enemies"`
+	// Blocks : 空中のブロック
+	Blocks []Block `json:"blocks"`
+	// Checkpoints : 中間地点のタイル位置
+	Checkpoints []int `json:"checkpoints"`
+	// Castle : 城のタイル位置
+	Castle int `json:"castle"`
+	// Flagpole : ゴールのポールのタイル位置（0の場合は城のFlagpoleTileNum手前）
+	Flagpole int `json:"flagpole"`
+	// Start : プレイヤーのスタートのタイル位置
+	Start int `json:"start"`
+
+	// tmx : Tiledで作成したマップ（TMXから読み込んだ場合のみ）
+	tmx *common.Level
+}
+
+// Cloud is a cloud in the sky of a course
+type Cloud struct {
+	// X : タイル位置（左端）
+	X int `json:"x"`
+	// Height : 高さ（タイル数）
+	Height int `json:"height"`
+}
+
+// Spawn is a spawn point of an enemy
+type Spawn struct {
+	// Type : 敵キャラの種類
+	Type int `json:"type"`
+	// X : タイル位置
+	X int `json:"x"`
+}
+
+// Block is a brick or a ? block floating above the ground
+type Block struct {
+	// Type : ブロックの種類（BlockBrick, BlockQuestion）
+	Type int `json:"type"`
+	// X : タイル位置
+	X int `json:"x"`
+	// Height : 地面からの高さ（タイル数、0の場合はBlockHeight）
+	Height int `json:"height"`
+	// Item : ？ブロックから出るアイテム（ItemCoin, ItemMushroom, ItemFireFlower, ItemStar）
+	Item int `json:"item"`
+}
+
+// isPit returns whether the tile x is a pit
+func (l *Level) isPit(x int) bool {
+	for _, v := range l.Pits {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// setDefaults fills the values omitted in a level file
+func (l *Level) setDefaults() {
+	if l.Name == "" {
+		l.Name = DefaultLevelName
+	}
+	if l.Width == 0 {
+		l.Width = TileNum
+	}
+	if l.Castle == 0 {
+		l.Castle = l.Width - GoalTileNum
+	}
+	if l.Flagpole == 0 {
+		l.Flagpole = l.Castle - FlagpoleTileNum
+	}
+	for i := range l.Blocks {
+		if l.Blocks[i].Height == 0 {
+			l.Blocks[i].Height = BlockHeight
+		}
+	}
+}
+
+// generateLevel builds a random course
+func generateLevel(rnd *rand.Rand) *Level {
+	level := &Level{Name: DefaultLevelName, Width: TileNum, Castle: TileNum - GoalTileNum, Flagpole: TileNum - GoalTileNum - FlagpoleTileNum}
+
+	// 作成済みの位置（範囲外参照を避けるため余分に確保）
+	pits := make([]bool, TileNum+MountTileNum+PipeTileNum+3)
+	mounts := make([]bool, len(pits))
+
+	// makingxxxx：作成状態（0:作成中でない 1:作成開始 2：それ以外）
+	makingFall := 0
+	// 残りの雲のTile数
+	makingCloud := 0
+
+	for i := 0; i <= TileNum; i++ {
+		// ------- 落とし穴 ------- //
+		// Start付近とGoal付近に落とし穴は作らない
+		if i >= StartTileNum && i < TileNum-AroundGoalTileNum {
+			randomNum := rnd.Intn(10)
+			if randomNum == 0 {
+				makingFall = 1
+			} else {
+				// 最低タイル2つ分は落とし穴を作成する
+				if makingFall == 1 {
+					makingFall = 2
+				} else {
+					makingFall = 0
+				}
+			}
+		}
+		if makingFall != 0 {
+			level.Pits = append(level.Pits, i)
+			pits[i] = true
+		}
+		// ------- 雲 ------- //
+		if makingCloud == 0 {
+			randomNum := rnd.Intn(12)
+			if randomNum < 3 {
+				makingCloud = CloudTileNum
+				level.Clouds = append(level.Clouds, Cloud{X: i, Height: randomNum})
+			}
+		}
+		if makingCloud != 0 {
+			makingCloud--
+		}
+	}
+	// ------- 山 ------- //
+	for i := 0; i <= TileNum; i++ {
+		makingMount := true
+		for j := 0; j < MountTileNum+2; j++ {
+			// 山を作成できる十分なスペースがない場合
+			if pits[i+j] {
+				makingMount = false
+			}
+		}
+		if makingMount && i < TileNum-AroundGoalTileNum {
+			level.Mountains = append(level.Mountains, i)
+			for j := 0; j < MountTileNum; j++ {
+				mounts[i+j] = true
+			}
+			i = i + MountIntervalTileNum
+		}
+	}
+	// ------- 土管 ------- //
+	for i := 0; i <= TileNum; i++ {
+		makingPipe := true
+		for j := 0; j < PipeTileNum+2; j++ {
+			// 土管を作成できる十分なスペースがない、もしくは山を作成している場合
+			if pits[i+j] || mounts[i+j] {
+				makingPipe = false
+			}
+		}
+		// Start付近とGoal付近は土管は作らない
+		if i >= StartTileNum && i < TileNum-AroundGoalTileNum {
+			if makingPipe {
+				level.Pipes = append(level.Pipes, i)
+				// 土管にはパックンフラワーを配置
+				level.Enemies = append(level.Enemies, Spawn{Type: EneymyType0, X: i})
+				i = i + PipeIntervalTileNum
+			}
+		}
+	}
+	// ------- 歩く敵キャラ ------- //
+	// 土管の位置
+	pipes := make([]bool, len(pits))
+	for _, v := range level.Pipes {
+		for j := 0; j < CellWidth32/CellWidth16; j++ {
+			pipes[v+j] = true
+		}
+	}
+	// 前の敵キャラの位置
+	lastWalker := 0
+	for i := StartTileNum; i < TileNum-AroundGoalTileNum; i++ {
+		// 敵キャラ（2タイル分）の下が地面で、前の敵キャラから離れている場合
+		if pits[i] || pits[i+1] || pipes[i] || pipes[i+1] || i-lastWalker < WalkerIntervalTileNum {
+			continue
+		}
+		randomNum := rnd.Intn(15)
+		if randomNum == 0 {
+			// クリボーが2、ノコノコが1の割合
+			spawn := Spawn{Type: EneymyType1, X: i}
+			if rnd.Intn(3) == 0 {
+				spawn.Type = EneymyType2
+			}
+			level.Enemies = append(level.Enemies, spawn)
+			lastWalker = i
+		}
+	}
+	// ------- ブロック ------- //
+	for i := StartTileNum; i < TileNum-AroundGoalTileNum; i++ {
+		randomNum := rnd.Intn(20)
+		if randomNum != 0 {
+			continue
+		}
+		// 1〜MaxBlockTileNum個のブロックを並べる
+		num := rnd.Intn(MaxBlockTileNum) + 1
+		makingBlock := true
+		for j := 0; j <= num; j++ {
+			// 土管の上（プレイヤーが通れない隙間ができる）と落とし穴の上（アイテムが落ちる）には置かない
+			if pipes[i+j] || pits[i+j] {
+				makingBlock = false
+			}
+		}
+		if !makingBlock {
+			continue
+		}
+		for j := 0; j < num; j++ {
+			block := Block{Type: BlockBrick, X: i + j, Height: BlockHeight}
+			// ？ブロックが1、レンガブロックが2の割合
+			if rnd.Intn(3) == 0 {
+				block.Type = BlockQuestion
+				// ？ブロックの中身はコインが多い
+				switch rnd.Intn(10) {
+				case 0, 1:
+					block.Item = ItemMushroom
+				case 2:
+					block.Item = ItemFireFlower
+				case 3:
+					block.Item = ItemStar
+				default:
+					block.Item = ItemCoin
+				}
+			}
+			level.Blocks = append(level.Blocks, block)
+		}
+		i = i + num + BlockIntervalTileNum
+	}
+	// ------- 中間地点 ------- //
+	// コースの真ん中から、落とし穴と土管のない位置を探す（プレイヤーは2タイル分）
+	for i := TileNum / 2; i < TileNum-AroundGoalTileNum; i++ {
+		if !pits[i] && !pits[i+1] && !pipes[i] && !pipes[i+1] {
+			level.Checkpoints = append(level.Checkpoints, i)
+			break
+		}
+	}
+	return level
+}
+
+// levelFromTMX converts a map made with the Tiled editor into a course.
+// The map must use 16x16 tiles. The tile layer "ground" defines the ground
+// (a column without any tile is a pit), the tile layer "pipes" the pipes,
+// the tile layer "background" is only drawn behind the course,
+// and the objects "enemy", "goal", "start", "brick", "question", "checkpoint" and "flagpole"
+// of the object layers define the enemy spawns, the castle, the start of the player, the blocks,
+// the checkpoints and the flagpole (by default in front of the castle).
+// The map properties "name" and "theme" set the name and the theme of the course.
+func levelFromTMX(tmx *common.Level) (*Level, error) {
+	// コースは16x16のタイルで組み立てる
+	if tmx.TileWidth != CellWidth16 || tmx.TileHeight != CellHeight16 {
+		return nil, fmt.Errorf("tiles of %dx%d, want %dx%d", tmx.TileWidth, tmx.TileHeight, CellWidth16, CellHeight16)
+	}
+	level := &Level{Width: tmx.Width(), tmx: tmx}
+	for _, property := range tmx.Properties {
+		switch property.Name {
+		case "name":
+			level.Name = property.Value
+		case "theme":
+			level.Theme, _ = strconv.Atoi(property.Value)
+		}
+	}
+	// 地面の上端（マップの下端を画面の下端に合わせる）
+	groundY := tmx.Height()*tmx.TileHeight - TileDepth*tmx.TileHeight
+
+	for _, layer := range tmx.TileLayers {
+		// タイルがある列
+		columns := make([]bool, tmx.Width()+1)
+		for _, tile := range layer.Tiles {
+			if tile.Image == nil || tile.Image.Width() == 0 {
+				continue
+			}
+			columns[int(tile.X)/tmx.TileWidth] = true
+		}
+		switch layer.Name {
+		case TMXLayerGround:
+			for i := 0; i < tmx.Width(); i++ {
+				if !columns[i] {
+					level.Pits = append(level.Pits, i)
+				}
+			}
+		case TMXLayerPipes:
+			for i := 0; i < tmx.Width(); i++ {
+				if columns[i] {
+					level.Pipes = append(level.Pipes, i)
+					// 土管は2タイル分
+					i++
+				}
+			}
+		}
+	}
+
+	for _, layer := range tmx.ObjectLayers {
+		for _, object := range layer.Objects {
+			x := int(object.X) / tmx.TileWidth
+			switch object.Type {
+			case TMXObjectEnemy:
+				spawn := Spawn{Type: EneymyType0, X: x}
+				for _, property := range object.Properties {
+					if property.Name == "type" {
+						spawn.Type, _ = strconv.Atoi(property.Value)
+					}
+				}
+				level.Enemies = append(level.Enemies, spawn)
+			case TMXObjectGoal:
+				level.Castle = x
+			case TMXObjectStart:
+				level.Start = x
+			case TMXObjectBrick, TMXObjectQuestion:
+				block := Block{Type: BlockBrick, X: x, Height: (groundY - int(object.Y)) / tmx.TileHeight}
+				if object.Type == TMXObjectQuestion {
+					block.Type = BlockQuestion
+				}
+				for _, property := range object.Properties {
+					if property.Name == "item" {
+						block.Item, _ = strconv.Atoi(property.Value)
+					}
+				}
+				level.Blocks = append(level.Blocks, block)
+			case TMXObjectCheckpoint:
+				level.Checkpoints = append(level.Checkpoints, x)
+			case TMXObjectFlagpole:
+				level.Flagpole = x
+			}
+		}
+	}
+	level.setDefaults()
+	return level, nil
+}
+
+// LoadLevelFile returns the course of a loaded level file (.json or .tmx)
+func LoadLevelFile(url string) (*Level, error) {
+	res, err := engo.Files.Resource(url)
+	if err != nil {
+		return nil, err
+	}
+	switch r := res.(type) {
+	case LevelResource:
+		return r.Level, nil
+	case common.TMXResource:
+		return levelFromTMX(r.Level)
+	}
+	return nil, fmt.Errorf("not a level file: %q", url)
+}
+
+// LevelResource contains a course loaded from a level file
+type LevelResource struct {
+	// Level holds the reference to the parsed level
+	Level *Level
+	url   string
+}
+
+// URL retrieves the url to the level file
+func (r LevelResource) URL() string {
+	return r.url
+}
+
+// levelLoader is responsible for managing '.json' level files within 'engo.Files'.
+type levelLoader struct {
+	levels map[string]LevelResource
+}
+
+// Load parses the level file
+func (l *levelLoader) Load(url string, data io.Reader) error {
+	level := &Level{}
+	if err := json.NewDecoder(data).Decode(level); err != nil {
+		return fmt.Errorf("unable to parse level %q: %s", url, err)
+	}
+	level.setDefaults()
+
+	l.levels[url] = LevelResource{Level: level, url: url}
+	return nil
+}
+
+// Unload removes the preloaded level from the cache
+func (l *levelLoader) Unload(url string) error {
+	delete(l.levels, url)
+	return nil
+}
+
+// Resource retrieves and returns the preloaded level of type 'LevelResource'
+func (l *levelLoader) Resource(url string) (engo.Resource, error) {
+	level, ok := l.levels[url]
+	if !ok {
+		return nil, fmt.Errorf("resource not loaded by `FileLoader`: %q", url)
+	}
+	return level, nil
+}
+
+func init() {
+	engo.Files.Register(".json", &levelLoader{levels: make(map[string]LevelResource)})
+}
@@ -0,0 +1,121 @@
+package systems
+
+import (
+	"encoding/json"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/EngoEngine/engo/common"
+)
+
+// testSeeds : コース生成のテストに使うシード
+var testSeeds = []int64{1, 2, 3, 42, 1234, 99999, -7}
+
+func TestGenerateLevelReproducible(t *testing.T) {
+	for _, seed := range testSeeds {
+		a := generateLevel(rand.New(rand.NewSource(seed)))
+		b := generateLevel(rand.New(rand.NewSource(seed)))
+		if !reflect.DeepEqual(a, b) {
+			t.Errorf("seed %d: generated two different courses", seed)
+		}
+	}
+}
+
+func TestGenerateLevelSeedsDiffer(t *testing.T) {
+	for i := 1; i < len(testSeeds); i++ {
+		a := generateLevel(rand.New(rand.NewSource(testSeeds[i-1])))
+		b := generateLevel(rand.New(rand.NewSource(testSeeds[i])))
+		if reflect.DeepEqual(a, b) {
+			t.Errorf("seeds %d and %d: generated the same course", testSeeds[i-1], testSeeds[i])
+		}
+	}
+}
+
+// blockedTiles returns the tiles of the pipes (2 tiles wide) and of the pits of the level
+func blockedTiles(level *Level) map[int]bool {
+	blocked := make(map[int]bool)
+	for _, v := range level.Pipes {
+		blocked[v] = true
+		blocked[v+1] = true
+	}
+	for _, v := range level.Pits {
+		blocked[v] = true
+	}
+	return blocked
+}
+
+func TestGenerateLevelBlocksAvoidPipesAndPits(t *testing.T) {
+	for seed := int64(1); seed <= 500; seed++ {
+		level := generateLevel(rand.New(rand.NewSource(seed)))
+		blocked := blockedTiles(level)
+		for _, block := range level.Blocks {
+			// ブロックの列とその右の列（プレイヤーは2タイル分）
+			if blocked[block.X] || blocked[block.X+1] {
+				t.Errorf("seed %d: block at %d over a pipe or a pit (pipes %v, pits %v)", seed, block.X, level.Pipes, level.Pits)
+			}
+		}
+	}
+}
+
+func TestLevelFilesBlocksAvoidPipesAndPits(t *testing.T) {
+	files, err := filepath.Glob("../assets/levels/*.json")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no level files: %v", err)
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		level := &Level{}
+		if err := json.Unmarshal(data, level); err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+		blocked := blockedTiles(level)
+		for _, block := range level.Blocks {
+			if blocked[block.X] || blocked[block.X+1] {
+				t.Errorf("%s: block at %d over a pipe or a pit (pipes %v, pits %v)", file, block.X, level.Pipes, level.Pits)
+			}
+		}
+	}
+}
+
+func TestGenerateLevelCheckpointOnGround(t *testing.T) {
+	for seed := int64(1); seed <= 500; seed++ {
+		level := generateLevel(rand.New(rand.NewSource(seed)))
+		blocked := blockedTiles(level)
+		// 中間地点からやり直すプレイヤー（2タイル分）の下が地面
+		for _, x := range level.Checkpoints {
+			if blocked[x] || blocked[x+1] {
+				t.Errorf("seed %d: checkpoint at %d on a pipe or a pit (pipes %v, pits %v)", seed, x, level.Pipes, level.Pits)
+			}
+		}
+	}
+}
+
+func TestLoadLevelFileNotLoaded(t *testing.T) {
+	// 読み込めなかったコースファイルはランダムなコースで代わりにしない
+	if level, err := LoadLevelFile("levels/missing.json"); err == nil {
+		t.Errorf("level %+v from a level file not loaded, want an error", level)
+	}
+}
+
+func TestLevelFromTMXTileSize(t *testing.T) {
+	for _, tt := range []struct {
+		width, height int
+		ok            bool
+	}{
+		{16, 16, true},
+		{8, 8, false},
+		{32, 32, false},
+		{16, 32, false},
+	} {
+		_, err := levelFromTMX(&common.Level{TileWidth: tt.width, TileHeight: tt.height})
+		if got := err == nil; got != tt.ok {
+			t.Errorf("tiles of %dx%d: loaded %v, want %v (%v)", tt.width, tt.height, got, tt.ok, err)
+		}
+	}
+}
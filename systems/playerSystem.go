@@ -1,65 +1,120 @@
 package systems
 
 import (
+	"image/color"
+
 	"github.com/EngoEngine/ecs"
 	"github.com/EngoEngine/engo"
 	"github.com/EngoEngine/engo/common"
 )
 
 const (
-	// MoveDistance : 移動距離
-	MoveDistance = 4
-	// JumpHeight : ジャンプの高さ
-	JumpHeight = 4
-	// MaxCount :
-	MaxCount = 40
-	// PlayerSpriteSheetCell : スプライトシートで使用する最初のセル番号
-	PlayerSpriteSheetCell = 8
+	// MoveSpeed : 移動速度（px/s）
+	MoveSpeed = 240
+	// WalkCellTime : 歩く動作の1コマの時間（秒）
+	WalkCellTime = 0.1
+	// RunCellTime : 走る動作の1コマの時間（秒）
+	RunCellTime = 0.05
+	// RunTime : 歩き続けて走り出すまでの時間（秒）
+	RunTime = 1
+	// SkidTime : 振り向いた時にブレーキの動作を表示する時間（秒）
+	SkidTime = 0.15
+	// Gravity : 重力加速度（px/s^2）
+	Gravity = 1440
+	// JumpSpeed : ジャンプの初速（px/s）
+	JumpSpeed = 480
+	// JumpCutSpeed : ジャンプボタンを離した時の上昇速度の上限（px/s）
+	JumpCutSpeed = 200
+	// BounceSpeed : 敵キャラを踏んだ時に跳ねる初速（px/s）
+	BounceSpeed = 300
+	// MaxFallSpeed : 最大落下速度（px/s）
+	MaxFallSpeed = 480
 	// ExtraSizeX : 　プレイヤー画像の余分サイズ
 	ExtraSizeX = 8
+	// SmallExtraSizeY : ちびマリオ画像の上の余分サイズ
+	SmallExtraSizeY = 16
+	// StarTime : スーパースターで無敵になる時間（秒）
+	StarTime = 10
+	// DamageTime : ダメージを受けた後に無敵になる時間（秒）
+	DamageTime = 2
+	// BlinkTime : 無敵の間の点滅の1コマの時間（秒）
+	BlinkTime = 0.05
+	// DiePauseTime : 死亡してから跳ね上がるまで止まっている時間（秒）
+	DiePauseTime = 0.5
+	// DieJumpSpeed : 死亡した時に跳ね上がる初速（px/s）
+	DieJumpSpeed = 480
+)
+
+const (
+	// PowerSmall : ちびマリオ
+	PowerSmall = 0
+	// PowerSuper : スーパーマリオ
+	PowerSuper = 1
+	// PowerFire : ファイアマリオ
+	PowerFire = 2
 )
 
+// powerCells : スプライトシートで使用するパワーアップの状態毎の最初のセル番号
+var powerCells = map[int]int{
+	PowerSmall: 0,
+	PowerSuper: 8,
+	PowerFire:  17,
+}
+
+// starColors : スーパースターで無敵の間の色
+var starColors = []color.Color{
+	color.White,
+	color.RGBA{255, 160, 160, 255},
+	color.RGBA{160, 255, 160, 255},
+	color.RGBA{160, 160, 255, 255},
+}
+
 var playerFile = "./Mario/Characters/Mario.png"
-var ifGameOver bool
 
 // Player is struct for the PlayerSystem
 type Player struct {
 	ecs.BasicEntity
 	common.RenderComponent
 	common.SpaceComponent
+	AnimationComponent
+	Collider
 	// Y初期値
 	playerPositionY float32
 	// 左右の足の位置
 	LeftPositionX  float32
 	RightPositionX float32
-	// カメラの進んだ距離
-	cameraMoveDistance int
+	// カメラの中心のX座標
+	cameraPositionX float32
 	// スプライトシート
 	spritesheet *common.Spritesheet
-	// 使用中のセル番号
-	useCell int
-	// ジャンプのカウント数
-	jumpCount int
-	// 2段ジャンプ用のカウント数
-	jumpCount2Step int
-	// 頂点までのカウント数
-	topCount int
-	// 着地点までのカウント数
-	bottomCount int
-	// ジャンプしているか
+	// 同じ向きに歩き続けている時間（秒）
+	walkTime float32
+	// ブレーキの動作の残り時間（秒）
+	skidTime float32
+	// 縦方向の速度（下向きが正）
+	velocityY float32
+	// 2段ジャンプできるか
 	ifJumping bool
-	// パイプ上にいるか
-	ifOnPipe bool
-	// 落下しているか
-	ifFalling bool
-	// スタートしたか
-	ifStart bool
+	// 地形の上にいるか
+	ifOnGround bool
+	// パワーアップの状態（PowerSmall, PowerSuper, PowerFire）
+	power int
+	// スーパースターの残り時間（秒）
+	starTime float32
+	// ダメージを受けた後の無敵の残り時間（秒）
+	damageTime float32
+	// 死亡してからの時間（秒）
+	dyingTime float32
 }
 
 // PlayerSystem create a Player to operate
 type PlayerSystem struct {
+	// Game : ゲームの状態
+	Game *GameState
+
 	world        *ecs.World
 	playerEntity *Player
+	input        *InputSystem
 }
 
 // Remove removes an Entity from the System
@@ -68,176 +123,286 @@ func (ps *PlayerSystem) Remove(ecs.BasicEntity) {
 		switch sys := system.(type) {
 		case *common.RenderSystem:
 			sys.Remove(ps.playerEntity.BasicEntity)
+		case *CollisionSystem:
+			sys.Remove(ps.playerEntity.BasicEntity)
+		case *AnimationSystem:
+			sys.Remove(ps.playerEntity.BasicEntity)
 		}
 	}
 }
 
 // Update is ran every frame, with `dt` being the time in seconds since the last frame
 func (ps *PlayerSystem) Update(dt float32) {
-	// 死亡したらリターン
-	if ifGameOver {
+	// 死亡中は操作できない
+	if ps.Game.State() == StateDying {
+		ps.updateDying(dt)
 		return
 	}
-	// スタートしていなければリターン
-	if !ps.playerEntity.ifStart {
+	// プレイ中でなければリターン
+	if ps.Game.State() != StatePlaying {
 		return
 	}
-	// Goal地点に達したら右移動はしない
-	if int(ps.playerEntity.LeftPositionX) >= (TileNum-GoalTileNum+2)*CellWidth16 {
-		for _, system := range ps.world.Systems() {
-			switch sys := system.(type) {
-			case *HUDTextSystem:
-				sys.TextInit(sys.TextEntity, TextGOAL)
-			}
-		}
-		ps.Remove(ps.playerEntity.BasicEntity)
-	}
-	// 落とし穴に落ちる
-	if ps.playerEntity.jumpCount == 0 {
-		if getMakingInfo(FallPoint, int(ps.playerEntity.LeftPositionX)) && getMakingInfo(FallPoint, int(ps.playerEntity.RightPositionX)) {
-			ps.playerEntity.ifFalling = true
-			ps.playerEntity.SpaceComponent.Position.Y += MoveDistance
-		}
-	}
+	ps.updateInvincible(dt)
+	// ゴールのポールとの接触はFlagpoleSystemで判定する
+	// 左右の足の位置（CollisionSystemで押し戻された位置に合わせる）
+	ps.playerEntity.LeftPositionX = ps.playerEntity.SpaceComponent.Position.X + float32(ExtraSizeX)
+	ps.playerEntity.RightPositionX = ps.playerEntity.SpaceComponent.Position.X + CellWidth32 - float32(ExtraSizeX)
+
 	if ps.playerEntity.SpaceComponent.Position.Y > engo.WindowHeight() {
 		ps.PlayerDie()
+		return
 	}
-	if ps.playerEntity.ifFalling {
+	// 落とし穴に落ちたら操作できない
+	if ps.playerEntity.SpaceComponent.Position.Y > ps.playerEntity.playerPositionY {
+		ps.applyGravity(dt)
 		return
 	}
 
-	// 通常時は動作なし
-	if ps.playerEntity.SpaceComponent.Position.Y == ps.playerEntity.playerPositionY {
-		ps.playerEntity.RenderComponent.Drawable = ps.playerEntity.spritesheet.Cell(PlayerSpriteSheetCell)
-	}
-	// 土管上にいる時も動作なし
-	if ps.playerEntity.ifOnPipe {
-		ps.playerEntity.RenderComponent.Drawable = ps.playerEntity.spritesheet.Cell(PlayerSpriteSheetCell)
+	// プレイヤーを左右に移動（両方押された場合は右を優先）
+	direction := 0
+	if ps.input.Down(ButtonMoveRight) {
+		direction = 1
+	} else if ps.input.Down(ButtonMoveLeft) {
+		direction = -1
 	}
-
-	// プレイヤーを右に移動
-	if engo.Input.Button("MoveRight").Down() {
-		// 土管位置で土管より下にいる場合
-		if getMakingInfo(PipePoint, int(ps.playerEntity.RightPositionX)) && int(ps.playerEntity.SpaceComponent.Position.Y) > int(engo.WindowHeight())-CellHeight16*8 {
-			// 右移動できない
-		} else {
-			// 土管上にいる かつ ジャンプ中でない
-			if ps.playerEntity.ifOnPipe && ps.playerEntity.jumpCount == 0 {
-				// 土管位置から外れた場合
-				if !getMakingInfo(PipePoint, int(ps.playerEntity.LeftPositionX)) && !getMakingInfo(PipePoint, int(ps.playerEntity.RightPositionX)) {
-					ps.playerEntity.ifOnPipe = false
-					ps.playerEntity.SpaceComponent.Position.Y = ps.playerEntity.playerPositionY
-				}
-			}
-			// 画面の真ん中より左に位置していれば、カメラを移動せずプレーヤーを移動する
-			if int(ps.playerEntity.SpaceComponent.Position.X) < ps.playerEntity.cameraMoveDistance+int(engo.WindowWidth())/2 {
-				ps.playerEntity.SpaceComponent.Position.X += MoveDistance
-				ps.playerEntity.LeftPositionX += MoveDistance
-				ps.playerEntity.RightPositionX += MoveDistance
-			} else {
-				// 画面の右端に達していなければプレイヤーを移動する
-				if int(ps.playerEntity.SpaceComponent.Position.X) < int(engo.WindowWidth())-CellWidth32 {
-					ps.playerEntity.SpaceComponent.Position.X += MoveDistance
-					ps.playerEntity.LeftPositionX += MoveDistance
-					ps.playerEntity.RightPositionX += MoveDistance
-				}
-				if int(ps.playerEntity.SpaceComponent.Position.X) < TileNum*CellWidth16-int(engo.WindowWidth()/2) {
-					// カメラを移動する
-					engo.Mailbox.Dispatch(common.CameraMessage{
-						Axis:        common.XAxis,
-						Value:       MoveDistance,
-						Incremental: true,
-					})
-				}
-				ps.playerEntity.cameraMoveDistance += MoveDistance
-			}
-
-		}
-		// ジャンプ中でない場合
-		if ps.playerEntity.jumpCount == 0 {
-			switch ps.playerEntity.useCell {
-			case 0:
-				ps.playerEntity.useCell = 1
-			case 1:
-				ps.playerEntity.useCell = 2
-			case 2:
-				ps.playerEntity.useCell = 3
-			case 3:
-				ps.playerEntity.useCell = 4
-			case 4:
-				ps.playerEntity.useCell = 0
-			}
-		} else {
-			ps.playerEntity.useCell = 3
+	if direction != 0 {
+		ps.moveHorizontally(MoveSpeed * dt * float32(direction))
+		// 地上で振り向いたらブレーキの動作をする
+		if ps.playerEntity.ifOnGround && ps.playerEntity.RenderComponent.Scale.X != float32(direction) {
+			ps.playerEntity.skidTime = SkidTime
+			ps.playerEntity.walkTime = 0
 		}
-		// プレイヤーの動作を変更
-		ps.playerEntity.RenderComponent.Drawable = ps.playerEntity.spritesheet.Cell(PlayerSpriteSheetCell + ps.playerEntity.useCell)
+		// 進行方向に向ける（左向きは画像を反転）
+		ps.playerEntity.RenderComponent.Scale.X = float32(direction)
+		ps.playerEntity.walkTime += dt
+		// カメラを移動する
+		ps.updateCamera()
+	} else {
+		ps.playerEntity.walkTime = 0
 	}
+	if ps.playerEntity.skidTime > 0 {
+		ps.playerEntity.skidTime -= dt
+	}
+	ps.updateAnimation(direction)
 
 	// プレイヤーをジャンプ
-	if engo.Input.Button("Jump").JustPressed() {
-		// 2段ジャンプ
-		if ps.playerEntity.ifJumping {
-			if ps.playerEntity.jumpCount < MaxCount/2 {
-				ps.playerEntity.jumpCount2Step = ps.playerEntity.jumpCount - 1
-			} else {
-				ps.playerEntity.jumpCount2Step = MaxCount - (ps.playerEntity.jumpCount - 1)
-			}
-			ps.playerEntity.jumpCount = 1
-			ps.playerEntity.ifJumping = false
-		}
-		// 初回ジャンプ
-		if ps.playerEntity.jumpCount == 0 {
-			ps.playerEntity.jumpCount2Step = 0
-			ps.playerEntity.jumpCount = 1
+	if ps.input.JustPressed(ButtonJump) {
+		if ps.playerEntity.ifOnGround {
+			// 初回ジャンプ
+			ps.playerEntity.velocityY = -JumpSpeed
 			ps.playerEntity.ifJumping = true
+		} else if ps.playerEntity.ifJumping {
+			// 2段ジャンプ
+			ps.playerEntity.velocityY = -JumpSpeed
+			ps.playerEntity.ifJumping = false
 		}
-		// 土管上からジャンプしていた場合
-		if ps.playerEntity.ifOnPipe {
-			ps.playerEntity.bottomCount = 1 + MaxCount + ps.playerEntity.jumpCount2Step + 8
-		} else { // 地面からジャンプしていた場合
-			ps.playerEntity.bottomCount = 1 + MaxCount + ps.playerEntity.jumpCount2Step
+	}
+	// ジャンプボタンを早く離すと低いジャンプになる
+	if ps.input.JustReleased(ButtonJump) {
+		ps.cutJump()
+	}
+
+	// 着地はCollisionSystemからの通知で判定する
+	ps.applyGravity(dt)
+}
+
+// cutJump limits the rising speed when the jump button is released, so that a short press makes a lower jump
+func (ps *PlayerSystem) cutJump() {
+	if ps.playerEntity.velocityY < -JumpCutSpeed {
+		ps.playerEntity.velocityY = -JumpCutSpeed
+	}
+}
+
+// updateDying moves the dead player: it stops for a moment, pops up and falls off the screen
+func (ps *PlayerSystem) updateDying(dt float32) {
+	player := ps.playerEntity
+	player.dyingTime += dt
+	if player.dyingTime < DiePauseTime || player.SpaceComponent.Position.Y > engo.WindowHeight() {
+		return
+	}
+	// 地形は無視して落ちる
+	player.SpaceComponent.Position.Y += player.velocityY * dt
+	player.velocityY += Gravity * dt
+	if player.velocityY > MaxFallSpeed {
+		player.velocityY = MaxFallSpeed
+	}
+}
+
+// updateInvincible counts down the time of the star and of the invincibility after a damage,
+// and makes the player flash or blink meanwhile
+func (ps *PlayerSystem) updateInvincible(dt float32) {
+	player := ps.playerEntity
+	if player.starTime > 0 {
+		player.starTime -= dt
+		player.RenderComponent.Color = starColors[int(player.starTime/BlinkTime)%len(starColors)]
+		if player.starTime <= 0 {
+			player.RenderComponent.Color = color.White
 		}
 	}
+	if player.damageTime > 0 {
+		player.damageTime -= dt
+		player.RenderComponent.Hidden = player.damageTime > 0 && int(player.damageTime/BlinkTime)%2 == 0
+	}
+}
 
-	if ps.playerEntity.jumpCount != 0 {
-		ps.playerEntity.jumpCount++
-		if ps.playerEntity.jumpCount <= ps.playerEntity.topCount {
-			// Up
-			ps.playerEntity.SpaceComponent.Position.Y -= JumpHeight
-		} else if ps.playerEntity.jumpCount <= ps.playerEntity.bottomCount {
-			if ps.playerEntity.SpaceComponent.Position.Y == onPipePositionY {
-				// 右足もしくは左足が土管上の場合
-				if getMakingInfo(PipePoint, int(ps.playerEntity.LeftPositionX)) || getMakingInfo(PipePoint, int(ps.playerEntity.RightPositionX)) {
-					ps.playerEntity.jumpCount = 0
-					ps.playerEntity.ifJumping = false
-					ps.playerEntity.ifOnPipe = true
-				} else {
-					// Down
-					ps.playerEntity.SpaceComponent.Position.Y += JumpHeight
-				}
-			} else {
-				// Down
-				ps.playerEntity.SpaceComponent.Position.Y += JumpHeight
-			}
+// updateAnimation switches the animation of the player to its movement
+func (ps *PlayerSystem) updateAnimation(direction int) {
+	player := ps.playerEntity
+	var name string
+	switch {
+	case !player.ifOnGround:
+		name = AnimationJump
+	case direction == 0:
+		name = AnimationIdle
+	case player.skidTime > 0:
+		name = AnimationSkid
+	case player.walkTime >= RunTime:
+		name = AnimationRun
+	default:
+		name = AnimationWalk
+	}
+	player.AnimationComponent.Play(&player.RenderComponent, name)
+}
 
-		} else {
-			ps.playerEntity.jumpCount = 0
+// playerAnimations returns the animations of the player in a power state
+func playerAnimations(power int) []*SpriteAnimation {
+	cell := powerCells[power]
+	return []*SpriteAnimation{
+		{Animation: common.Animation{Name: AnimationIdle, Frames: []int{cell}, Loop: true}, Rate: WalkCellTime},
+		{Animation: common.Animation{Name: AnimationWalk, Frames: []int{cell + 1, cell + 2, cell + 3}, Loop: true}, Rate: WalkCellTime},
+		{Animation: common.Animation{Name: AnimationRun, Frames: []int{cell + 1, cell + 2, cell + 3}, Loop: true}, Rate: RunCellTime},
+		{Animation: common.Animation{Name: AnimationSkid, Frames: []int{cell + 4}, Loop: true}, Rate: WalkCellTime},
+		{Animation: common.Animation{Name: AnimationJump, Frames: []int{cell + 5}, Loop: true}, Rate: WalkCellTime},
+		{Animation: common.Animation{Name: AnimationClimb, Frames: []int{cell + 7}, Loop: true}, Rate: WalkCellTime},
+		// 死亡はどの状態でもちびマリオの画像
+		{Animation: common.Animation{Name: AnimationDie, Frames: []int{powerCells[PowerSmall] + 6}, Loop: true}, Rate: WalkCellTime},
+	}
+}
+
+// setPower changes the power state of the player, with its sprite and its hitbox
+func (ps *PlayerSystem) setPower(power int) {
+	ps.playerEntity.power = power
+	// ちびマリオは当たり判定が小さい
+	ps.playerEntity.Collider.Inset.Y = 0
+	if power == PowerSmall {
+		ps.playerEntity.Collider.Inset.Y = SmallExtraSizeY
+	}
+	// 表示中のアニメーションを新しい状態の画像で表示し直す
+	name := ps.playerEntity.AnimationComponent.Playing()
+	if name == "" {
+		name = AnimationIdle
+	}
+	ps.playerEntity.AnimationComponent.AddAnimations(playerAnimations(power))
+	ps.playerEntity.AnimationComponent.Restart(&ps.playerEntity.RenderComponent, name)
+}
+
+// moveHorizontally moves the player by dx, within the course and the camera view, unless the terrain blocks it
+func (ps *PlayerSystem) moveHorizontally(dx float32) {
+	x := ps.playerEntity.SpaceComponent.Position.X
+	// 画面の左端より左には移動できない
+	if min := ps.playerEntity.cameraPositionX - engo.WindowWidth()/2 - ExtraSizeX; x+dx < min {
+		dx = min - x
+	}
+	// コースの右端より右には移動できない
+	if max := float32(ps.Game.Level.Width*CellWidth16) - CellWidth32 + ExtraSizeX; x+dx > max {
+		dx = max - x
+	}
+	// 地形（土管など）がある場合は移動できない
+	if dx == 0 || ps.isBlocked(dx) {
+		return
+	}
+	ps.playerEntity.SpaceComponent.Position.X += dx
+	ps.playerEntity.LeftPositionX += dx
+	ps.playerEntity.RightPositionX += dx
+}
+
+// updateCamera scrolls the camera to keep the player at the center of the screen.
+// Like the original game the camera only scrolls rightwards, and stops at the end of the course.
+func (ps *PlayerSystem) updateCamera() {
+	x := ps.playerEntity.SpaceComponent.Position.X + CellWidth32/2
+	if max := float32(ps.Game.Level.Width*CellWidth16) - engo.WindowWidth()/2; x > max {
+		x = max
+	}
+	if min := engo.WindowWidth() / 2; x < min {
+		x = min
+	}
+	if x <= ps.playerEntity.cameraPositionX {
+		return
+	}
+	ps.playerEntity.cameraPositionX = x
+	engo.Mailbox.Dispatch(common.CameraMessage{
+		Axis:        common.XAxis,
+		Value:       x,
+		Incremental: false,
+	})
+}
+
+// applyGravity accelerates the player downwards and moves it by its vertical velocity
+func (ps *PlayerSystem) applyGravity(dt float32) {
+	ps.playerEntity.velocityY += Gravity * dt
+	if ps.playerEntity.velocityY > MaxFallSpeed {
+		ps.playerEntity.velocityY = MaxFallSpeed
+	}
+	ps.playerEntity.ifOnGround = false
+	ps.playerEntity.SpaceComponent.Position.Y += ps.playerEntity.velocityY * dt
+}
+
+// onCollision handles the contacts of the player reported by the CollisionSystem
+func (ps *PlayerSystem) onCollision(contact Contact) {
+	if ps.Game.State() != StatePlaying || contact.Entity.ID() != ps.playerEntity.ID() {
+		return
+	}
+	// 敵キャラ、アイテムとの接触はEnermySystem, BlockSystemで判定する
+	switch contact.Group {
+	case GroupTerrain:
+		switch contact.Side {
+		case SideBottom:
+			// 着地
+			ps.playerEntity.velocityY = 0
 			ps.playerEntity.ifJumping = false
-			// 着地点が土管上の場合
-			if getMakingInfo(PipePoint, int(ps.playerEntity.LeftPositionX)) || getMakingInfo(PipePoint, int(ps.playerEntity.RightPositionX)) {
-				ps.playerEntity.ifOnPipe = true
-			} else { // 着地点が地面の場合
-				ps.playerEntity.ifOnPipe = false
+			ps.playerEntity.ifOnGround = true
+		case SideTop:
+			// 頭をぶつけたら落下する
+			if ps.playerEntity.velocityY < 0 {
+				ps.playerEntity.velocityY = 0
 			}
 		}
 	}
 }
 
+// bounds returns the hitbox of the player moved by dx, dy
+func (ps *PlayerSystem) bounds(dx, dy float32) engo.AABB {
+	return engo.AABB{
+		Min: engo.Point{X: ps.playerEntity.LeftPositionX + dx, Y: ps.playerEntity.SpaceComponent.Position.Y + ps.playerEntity.Collider.Inset.Y + dy},
+		Max: engo.Point{X: ps.playerEntity.RightPositionX + dx, Y: ps.playerEntity.SpaceComponent.Position.Y + CellHeight32 + dy},
+	}
+}
+
+// isBlocked returns whether the terrain blocks the player moving by dx
+func (ps *PlayerSystem) isBlocked(dx float32) bool {
+	cell, _ := ps.Game.CollisionMap.Solid(ps.bounds(dx, 0))
+	return cell != CellEmpty
+}
+
 // New is the initialisation of the System
 func (ps *PlayerSystem) New(w *ecs.World) {
 	//　Worldの追加
 	ps.world = w
+	// 入力の取得
+	for _, system := range ps.world.Systems() {
+		switch sys := system.(type) {
+		case *InputSystem:
+			ps.input = sys
+		}
+	}
+	// 接触の通知
+	engo.Mailbox.Listen("CollisionMessage", func(msg engo.Message) {
+		contact, ok := msg.(CollisionMessage)
+		if !ok {
+			return
+		}
+		ps.onCollision(contact.Contact)
+	})
 	//　Entity生成
 	player := Player{BasicEntity: ecs.NewBasic()}
 
@@ -246,32 +411,38 @@ func (ps *PlayerSystem) New(w *ecs.World) {
 	// カメラ設定
 	common.CameraBounds = engo.AABB{
 		Min: engo.Point{X: 0, Y: 0},
-		Max: engo.Point{X: 3200, Y: 300},
+		Max: engo.Point{X: float32(ps.Game.Level.Width * CellWidth16), Y: 300},
 	}
 }
 
 // PlayerInit initializes the value of PlayerEntity
 func (ps *PlayerSystem) PlayerInit(player *Player) {
 
-	// XY初期値
-	PsPositionX := float32(0)
+	// XY初期値（中間地点を通過していればそこから）
+	start := ps.Game.Level.Start
+	if checkpoint := ps.Game.Checkpoint(); checkpoint > 0 {
+		start = checkpoint
+	}
+	PsPositionX := float32(start * CellWidth16)
 	PsPositionY := engo.WindowHeight() - CellHeight16*6
 
 	// SpaceComponent
 	player.SpaceComponent = common.SpaceComponent{
 		Position: engo.Point{X: PsPositionX, Y: PsPositionY},
-		Width:    30,
-		Height:   30,
+		Width:    CellWidth32,
+		Height:   CellHeight32,
 	}
 
 	// スプライトシート
 	player.spritesheet = common.NewSpritesheetWithBorderFromFile(playerFile, 32, 32, 0, 0)
 	// RenderComponent
 	player.RenderComponent = common.RenderComponent{
-		Drawable: player.spritesheet.Cell(PlayerSpriteSheetCell),
+		Drawable: player.spritesheet.Cell(powerCells[PowerSmall]),
 		Scale:    engo.Point{X: 1, Y: 1},
 	}
 	player.RenderComponent.SetZIndex(5)
+	// AnimationComponent
+	player.AnimationComponent = NewAnimationComponent(player.spritesheet, playerAnimations(PowerSmall))
 
 	// コンポーネントセット
 	ps.playerEntity = player
@@ -280,38 +451,118 @@ func (ps *PlayerSystem) PlayerInit(player *Player) {
 	ps.playerEntity.playerPositionY = PsPositionY
 	ps.playerEntity.LeftPositionX = PsPositionX + float32(ExtraSizeX)
 	ps.playerEntity.RightPositionX = PsPositionX + CellWidth32 - float32(ExtraSizeX)
-	ps.playerEntity.ifFalling = false
-	ps.playerEntity.ifOnPipe = false
-	ps.playerEntity.cameraMoveDistance = 0
-	ps.playerEntity.topCount = 1 + MaxCount/2
-	ps.playerEntity.bottomCount = 0
-	ps.playerEntity.ifStart = false
-	ifGameOver = false
-
-	// RenderSystemに追加
+	ps.playerEntity.cameraPositionX = 0
+	ps.playerEntity.walkTime = 0
+	ps.playerEntity.skidTime = 0
+	ps.playerEntity.velocityY = 0
+	ps.playerEntity.ifJumping = false
+	ps.playerEntity.ifOnGround = true
+	ps.playerEntity.starTime = 0
+	ps.playerEntity.damageTime = 0
+	ps.playerEntity.dyingTime = 0
+	ps.playerEntity.Collider = Collider{
+		Group:   GroupPlayer,
+		Inset:   engo.Point{X: ExtraSizeX},
+		Terrain: true,
+	}
+	ps.setPower(PowerSmall)
+
+	// RenderSystem, CollisionSystem, AnimationSystemに追加
 	for _, system := range ps.world.Systems() {
 		switch sys := system.(type) {
 		case *common.RenderSystem:
 			sys.Add(&player.BasicEntity, &player.RenderComponent, &player.SpaceComponent)
+		case *CollisionSystem:
+			sys.Add(&player.BasicEntity, &player.SpaceComponent, &player.Collider)
+		case *AnimationSystem:
+			sys.Add(&player.BasicEntity, &player.AnimationComponent.AnimationComponent, &player.RenderComponent)
 		}
 	}
 
-	// カメラを移動する
-	engo.Mailbox.Dispatch(common.CameraMessage{
-		Axis:        common.XAxis,
-		Value:       engo.WindowWidth() / 2,
-		Incremental: false,
-	})
+	// カメラをプレイヤーに合わせる
+	ps.updateCamera()
+}
+
+// PlayerBounce makes the player bounce after stomping an enemy
+func (ps *PlayerSystem) PlayerBounce() {
+	ps.playerEntity.velocityY = -BounceSpeed
+	ps.playerEntity.ifOnGround = false
+}
+
+// PlayerGetItem is a function when the Player gets an item
+func (ps *PlayerSystem) PlayerGetItem(item int) {
+	ps.Game.Progress.AddScore(ItemScore)
+	switch item {
+	case ItemMushroom:
+		if ps.playerEntity.power == PowerSmall {
+			ps.setPower(PowerSuper)
+		}
+	case ItemFireFlower:
+		// ちびマリオはスーパーマリオ、それ以外はファイアマリオになる
+		if ps.playerEntity.power == PowerSmall {
+			ps.setPower(PowerSuper)
+		} else {
+			ps.setPower(PowerFire)
+		}
+	case ItemStar:
+		ps.playerEntity.starTime = StarTime
+	}
 }
 
-// PlayerDie is a function when the Player dies
+// PlayerDamage is a function when the Player is hit by an enemy.
+// A big player becomes small instead of dying
+func (ps *PlayerSystem) PlayerDamage() {
+	// 無敵の間はダメージを受けない
+	if ps.playerEntity.starTime > 0 || ps.playerEntity.damageTime > 0 {
+		return
+	}
+	if ps.playerEntity.power == PowerSmall {
+		ps.PlayerDie()
+		return
+	}
+	ps.setPower(PowerSmall)
+	ps.playerEntity.damageTime = DamageTime
+}
+
+// Power returns the power state of the player
+func (ps *PlayerSystem) Power() int {
+	return ps.playerEntity.power
+}
+
+// HasStar returns whether the player is invincible with a star, and defeats the enemies it touches
+func (ps *PlayerSystem) HasStar() bool {
+	return ps.playerEntity.starTime > 0
+}
+
+// IsBig returns whether the player is big and can break bricks
+func (ps *PlayerSystem) IsBig() bool {
+	return ps.playerEntity.power != PowerSmall
+}
+
+// PlayerDie is a function when the Player dies.
+// The world stops while the player shows the death sprite, pops up and falls off the screen
 func (ps *PlayerSystem) PlayerDie() {
-	ifGameOver = true
+	// 残り人数を減らす（0になるとゲームオーバー）
+	ps.Game.Progress.Lives--
+	ps.Game.SetState(StateDying)
+
+	player := ps.playerEntity
+	player.dyingTime = 0
+	player.starTime = 0
+	player.damageTime = 0
+	player.RenderComponent.Color = color.White
+	player.RenderComponent.Hidden = false
+	player.AnimationComponent.Play(&player.RenderComponent, AnimationDie)
+	// 落とし穴に落ちた場合は跳ね上がらない
+	player.velocityY = -DieJumpSpeed
+	if player.SpaceComponent.Position.Y > engo.WindowHeight() {
+		player.velocityY = 0
+	}
+	// 敵キャラ、アイテムに触れないようCollisionSystemから削除
 	for _, system := range ps.world.Systems() {
 		switch sys := system.(type) {
-		case *HUDTextSystem:
-			sys.TextInit(sys.TextEntity, TextEND)
+		case *CollisionSystem:
+			sys.Remove(player.BasicEntity)
 		}
 	}
-	ps.Remove(ps.playerEntity.BasicEntity)
 }
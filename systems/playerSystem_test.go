@@ -0,0 +1,171 @@
+package systems
+
+import (
+	"testing"
+
+	"github.com/EngoEngine/engo"
+)
+
+// jumpHeight returns the height (px) of a jump from the ground with the jump button released after the time (s)
+func jumpHeight(release float32) float32 {
+	const dt = float32(1) / 60
+	ps := &PlayerSystem{playerEntity: &Player{}}
+	ps.playerEntity.velocityY = -JumpSpeed
+	top := float32(0)
+	for t := float32(0); ps.playerEntity.velocityY < 0; t += dt {
+		if t >= release {
+			ps.cutJump()
+		}
+		ps.applyGravity(dt)
+		if y := ps.playerEntity.SpaceComponent.Position.Y; y < top {
+			top = y
+		}
+	}
+	return -top
+}
+
+func TestJumpCutHeight(t *testing.T) {
+	// ボタンを押し続けると初速と重力で決まる高さまで上がる
+	full := jumpHeight(10)
+	if want := float32(JumpSpeed*JumpSpeed) / (2 * Gravity); full < want-8 || full > want+8 {
+		t.Errorf("full jump %vpx, want about %vpx", full, want)
+	}
+	// すぐに離すと上昇速度がJumpCutSpeedに抑えられる
+	short := jumpHeight(0)
+	if want := float32(JumpCutSpeed*JumpCutSpeed) / (2 * Gravity); short < want-4 || short > want+4 {
+		t.Errorf("short jump %vpx, want about %vpx", short, want)
+	}
+	// 離すのが遅いほど高い（頂点の後に離しても変わらない）
+	middle := jumpHeight(0.1)
+	if !(short < middle && middle < full) {
+		t.Errorf("jump heights %v, %v, %v: want higher for a longer press", short, middle, full)
+	}
+	if late := jumpHeight(1); late != full {
+		t.Errorf("released after the top: %vpx, want %vpx", late, full)
+	}
+}
+
+func TestCameraBoundsPlayer(t *testing.T) {
+	// 画面サイズの設定
+	engo.Run(engo.RunOptions{HeadlessMode: true, NoRun: true, Width: 480, Height: 320}, &headlessScene{})
+	half := engo.WindowWidth() / 2
+	ps := &PlayerSystem{Game: &GameState{Level: &Level{Width: 100}, CollisionMap: NewCollisionMap(101, 20)}, playerEntity: &Player{}}
+	player := ps.playerEntity
+	player.cameraPositionX = half
+
+	// スタート地点では画面（コース）の左端より左に行けない
+	player.SpaceComponent.Position.X = 100
+	ps.moveHorizontally(-200)
+	if x := player.SpaceComponent.Position.X; x != -ExtraSizeX {
+		t.Errorf("moved left from the start to %v, want %v", x, -ExtraSizeX)
+	}
+
+	// 右に進むとカメラがプレイヤーを追う
+	ps.moveHorizontally(1000 + ExtraSizeX)
+	ps.updateCamera()
+	if want := float32(1000 + CellWidth32/2); player.cameraPositionX != want {
+		t.Errorf("camera at %v, want %v", player.cameraPositionX, want)
+	}
+	// 左に戻ってもカメラは戻らず、画面の左端で止まる
+	ps.moveHorizontally(-1000)
+	ps.updateCamera()
+	if want := float32(1000 + CellWidth32/2); player.cameraPositionX != want {
+		t.Errorf("camera at %v after moving left, want %v", player.cameraPositionX, want)
+	}
+	if want := player.cameraPositionX - half - ExtraSizeX; player.SpaceComponent.Position.X != want {
+		t.Errorf("moved left to %v, want the left of the screen %v", player.SpaceComponent.Position.X, want)
+	}
+
+	// コースの右端ではカメラもプレイヤーも止まる
+	ps.moveHorizontally(5000)
+	ps.updateCamera()
+	if want := float32(100*CellWidth16) - half; player.cameraPositionX != want {
+		t.Errorf("camera at %v at the end of the course, want %v", player.cameraPositionX, want)
+	}
+	if want := float32(100*CellWidth16) - CellWidth32 + ExtraSizeX; player.SpaceComponent.Position.X != want {
+		t.Errorf("moved right to %v, want the end of the course %v", player.SpaceComponent.Position.X, want)
+	}
+}
+
+func TestPowerUpAndDown(t *testing.T) {
+	sim := newTestSimulation(1, flatLevelFile)
+	sim.Start()
+	ps := sim.player
+
+	// キノコでスーパーマリオ、ファイアフラワーでファイアマリオになる
+	for _, tt := range []struct {
+		item int
+		want int
+	}{
+		{ItemFireFlower, PowerSuper},
+		{ItemMushroom, PowerSuper},
+		{ItemFireFlower, PowerFire},
+		{ItemMushroom, PowerFire},
+	} {
+		ps.PlayerGetItem(tt.item)
+		if got := ps.Power(); got != tt.want {
+			t.Fatalf("after item %d: power %d, want %d", tt.item, got, tt.want)
+		}
+	}
+	if ps.playerEntity.Collider.Inset.Y != 0 {
+		t.Errorf("big player hitbox inset %v, want 0", ps.playerEntity.Collider.Inset.Y)
+	}
+
+	// ダメージを受けるとちびマリオに戻り、しばらくは無敵
+	ps.PlayerDamage()
+	if ps.Power() != PowerSmall || ps.playerEntity.Collider.Inset.Y != SmallExtraSizeY {
+		t.Errorf("after a damage: power %d inset %v, want PowerSmall %v", ps.Power(), ps.playerEntity.Collider.Inset.Y, SmallExtraSizeY)
+	}
+	ps.PlayerDamage()
+	if sim.State() != StatePlaying {
+		t.Errorf("state %d after a damage while blinking, want StatePlaying", sim.State())
+	}
+
+	// スーパースターで無敵の間もダメージを受けない
+	sim.Step(int(DamageTime*60) + 1)
+	ps.PlayerGetItem(ItemStar)
+	ps.PlayerDamage()
+	if sim.State() != StatePlaying || !ps.HasStar() {
+		t.Errorf("state %d star %v after a damage with a star, want StatePlaying true", sim.State(), ps.HasStar())
+	}
+
+	// 無敵が切れたちびマリオは死亡する
+	sim.Step(int(StarTime*60) + 1)
+	ps.PlayerDamage()
+	if sim.State() != StateDying {
+		t.Errorf("state %d after a damage of the small player, want StateDying", sim.State())
+	}
+}
+
+func TestDeathSequence(t *testing.T) {
+	sim := newTestSimulation(1, flatLevelFile)
+	sim.Start()
+	player := sim.player.playerEntity
+	start := sim.PlayerPosition()
+
+	// 死亡すると止まってから跳ね上がり、画面の下に落ちる
+	sim.player.PlayerDie()
+	if got := player.Playing(); got != AnimationDie {
+		t.Errorf("animation %q after dying, want %q", got, AnimationDie)
+	}
+	sim.Step(int(DiePauseTime*60) - 1)
+	if p := sim.PlayerPosition(); p != start {
+		t.Errorf("player at %v during the pause, want %v", p, start)
+	}
+	top := start.Y
+	for i := 0; i < DyingTime*60-int(DiePauseTime*60) && sim.State() == StateDying; i++ {
+		sim.Step(1)
+		if y := sim.PlayerPosition().Y; y < top {
+			top = y
+		}
+	}
+	if top >= start.Y {
+		t.Errorf("player did not pop up after dying")
+	}
+	if y := sim.PlayerPosition().Y; y <= SimulationHeight {
+		t.Errorf("player at y %v at the end of the death sequence, want below the screen (%v)", y, SimulationHeight)
+	}
+	if sim.State() != StateDying {
+		t.Errorf("state %d before %vs, want StateDying", sim.State(), DyingTime)
+	}
+}
@@ -0,0 +1,63 @@
+package systems
+
+const (
+	// StartLives : 最初の残り人数
+	StartLives = 3
+	// CoinsPerLife : 1UPに必要なコインの枚数
+	CoinsPerLife = 100
+)
+
+// Progress is the score, the coins and the lives of the player, and the courses reached.
+// It is kept by the scene across the courses of a game, while the GameState is rebuilt for each course.
+type Progress struct {
+	// Score : スコア
+	Score int
+	// Coins : コインの枚数
+	Coins int
+	// Lives : 残り人数
+	Lives int
+	// Course : 現在のコースの番号（Courses）
+	Course int
+	// Unlocked : ワールドセレクトで選べるコースの数
+	Unlocked int
+}
+
+// NewProgress creates the progress of a new game
+func NewProgress() *Progress {
+	progress := &Progress{Unlocked: 1}
+	progress.Reset()
+	return progress
+}
+
+// Reset starts a new game (the courses unlocked are kept)
+func (p *Progress) Reset() {
+	p.Score = 0
+	p.Coins = 0
+	p.Lives = StartLives
+}
+
+// AddScore adds points to the score
+func (p *Progress) AddScore(points int) {
+	p.Score += points
+}
+
+// AddCoin adds a coin, and a life every CoinsPerLife coins
+func (p *Progress) AddCoin() {
+	p.Coins++
+	if p.Coins >= CoinsPerLife {
+		p.Coins -= CoinsPerLife
+		p.Lives++
+	}
+}
+
+// NextCourse returns whether there is a course after the current one
+func (p *Progress) NextCourse() bool {
+	return p.Course+1 < len(Courses)
+}
+
+// ClearCourse unlocks the course after the current one
+func (p *Progress) ClearCourse() {
+	if p.NextCourse() && p.Unlocked < p.Course+2 {
+		p.Unlocked = p.Course + 2
+	}
+}
@@ -0,0 +1,69 @@
+package systems
+
+import "testing"
+
+func TestProgressAddCoin(t *testing.T) {
+	p := NewProgress()
+	for i := 0; i < CoinsPerLife-1; i++ {
+		p.AddCoin()
+	}
+	if p.Coins != CoinsPerLife-1 || p.Lives != StartLives {
+		t.Fatalf("after %d coins: coins %d lives %d, want %d %d", CoinsPerLife-1, p.Coins, p.Lives, CoinsPerLife-1, StartLives)
+	}
+	// 100枚目で1UPしてコインの枚数は0に戻る
+	p.AddCoin()
+	if p.Coins != 0 || p.Lives != StartLives+1 {
+		t.Errorf("after %d coins: coins %d lives %d, want 0 %d", CoinsPerLife, p.Coins, p.Lives, StartLives+1)
+	}
+	p.AddCoin()
+	if p.Coins != 1 || p.Lives != StartLives+1 {
+		t.Errorf("after %d coins: coins %d lives %d, want 1 %d", CoinsPerLife+1, p.Coins, p.Lives, StartLives+1)
+	}
+}
+
+func TestProgressAddScore(t *testing.T) {
+	p := NewProgress()
+	p.AddScore(100)
+	p.AddScore(50)
+	if want := 150; p.Score != want {
+		t.Errorf("score %d, want %d", p.Score, want)
+	}
+	p.Reset()
+	if p.Score != 0 || p.Coins != 0 || p.Lives != StartLives {
+		t.Errorf("after reset: score %d coins %d lives %d, want 0 0 %d", p.Score, p.Coins, p.Lives, StartLives)
+	}
+}
+
+func TestProgressClearCourse(t *testing.T) {
+	p := NewProgress()
+	if p.Unlocked != 1 {
+		t.Fatalf("unlocked %d at start, want 1", p.Unlocked)
+	}
+	// クリアすると次のコースを選べる
+	p.ClearCourse()
+	if p.Unlocked != 2 {
+		t.Errorf("after clearing the first course: unlocked %d, want 2", p.Unlocked)
+	}
+	// 前のコースをクリアし直しても減らない
+	p.Course = 2
+	p.ClearCourse()
+	p.Course = 0
+	p.ClearCourse()
+	if p.Unlocked != 4 {
+		t.Errorf("after clearing the third course: unlocked %d, want 4", p.Unlocked)
+	}
+	// 最後のコースの後にコースはない
+	p.Course = len(Courses) - 1
+	if p.NextCourse() {
+		t.Errorf("next course after the last course")
+	}
+	p.ClearCourse()
+	if p.Unlocked != len(Courses) {
+		t.Errorf("after clearing the last course: unlocked %d, want %d", p.Unlocked, len(Courses))
+	}
+	// ゲームオーバーでも選べるコースは残る
+	p.Reset()
+	if p.Unlocked != len(Courses) {
+		t.Errorf("after reset: unlocked %d, want %d", p.Unlocked, len(Courses))
+	}
+}
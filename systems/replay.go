@@ -0,0 +1,119 @@
+package systems
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// Replay is a recording of the buttons pressed at every step of a run.
+// Played back with the same course, it reproduces the run exactly.
+type Replay struct {
+	// Seed : コース生成のシード
+	Seed int64 `json:"seed"`
+	// LevelFile : コースファイル（ランダムなコースの場合は空）
+	LevelFile string `json:"level,omitempty"`
+	// Steps : ステップごとに押されていたボタン（Buttonsの順のビット）
+	Steps []int `json:"steps"`
+}
+
+// LoadReplay reads a replay file
+func LoadReplay(path string) (*Replay, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	replay := &Replay{}
+	if err := json.Unmarshal(data, replay); err != nil {
+		return nil, err
+	}
+	return replay, nil
+}
+
+// Save writes the replay file
+func (r *Replay) Save(path string) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// buttonsToStep packs the pressed buttons into the bits of a step
+func buttonsToStep(pressed func(name string) bool) int {
+	step := 0
+	for i, name := range Buttons {
+		if pressed(name) {
+			step |= 1 << i
+		}
+	}
+	return step
+}
+
+// stepButtons returns the buttons pressed at a step
+func stepButtons(step int) []string {
+	buttons := make([]string, 0)
+	for i, name := range Buttons {
+		if step&(1<<i) != 0 {
+			buttons = append(buttons, name)
+		}
+	}
+	return buttons
+}
+
+// RecordingInput is an InputSource recording the buttons of another InputSource into a Replay
+type RecordingInput struct {
+	// Source : 記録する入力元
+	Source InputSource
+	// Replay : 記録先
+	Replay Replay
+}
+
+// Update reads the buttons of the source and records them
+func (r *RecordingInput) Update() {
+	r.Source.Update()
+	r.Replay.Steps = append(r.Replay.Steps, buttonsToStep(r.Source.ButtonDown))
+}
+
+// ButtonDown returns whether the button is pressed at the current step
+func (r *RecordingInput) ButtonDown(name string) bool {
+	return r.Source.ButtonDown(name)
+}
+
+// ReplayInput is an InputSource playing back the buttons of a Replay.
+// All the buttons are released after the last step.
+type ReplayInput struct {
+	replay *Replay
+	// 次のステップ
+	next int
+	// 現在のステップ
+	step int
+}
+
+// NewReplayInput creates an InputSource playing back the replay from its first step
+func NewReplayInput(replay *Replay) *ReplayInput {
+	return &ReplayInput{replay: replay}
+}
+
+// Update moves to the next step of the replay
+func (r *ReplayInput) Update() {
+	r.step = 0
+	if r.next < len(r.replay.Steps) {
+		r.step = r.replay.Steps[r.next]
+	}
+	r.next++
+}
+
+// ButtonDown returns whether the button is pressed at the current step
+func (r *ReplayInput) ButtonDown(name string) bool {
+	for i, button := range Buttons {
+		if button == name {
+			return r.step&(1<<i) != 0
+		}
+	}
+	return false
+}
+
+// Finished returns whether all the steps of the replay were played
+func (r *ReplayInput) Finished() bool {
+	return r.next >= len(r.replay.Steps)
+}
@@ -0,0 +1,108 @@
+package systems
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// scriptPart holds the buttons during a number of steps
+type scriptPart struct {
+	frames  int
+	buttons []string
+}
+
+// replayScript : タイトルで開始して、右に歩いてジャンプする
+var replayScript = []scriptPart{
+	{1, []string{ButtonEnter}},
+	{20, []string{ButtonMoveRight}},
+	{10, []string{ButtonMoveRight, ButtonJump}},
+	{15, nil},
+	{25, []string{ButtonMoveRight}},
+	{5, []string{ButtonMoveLeft}},
+}
+
+// recordScript records the script through a RecordingInput and returns the replay
+func recordScript(script []scriptPart) *Replay {
+	scripted := &ScriptedInput{}
+	recorder := &RecordingInput{Source: scripted}
+	for _, part := range script {
+		scripted.Hold(part.buttons...)
+		for i := 0; i < part.frames; i++ {
+			recorder.Update()
+		}
+	}
+	return &recorder.Replay
+}
+
+func TestReplayRoundTrip(t *testing.T) {
+	replay := recordScript(replayScript)
+	replay.Seed = pitSeed
+	replay.LevelFile = flatLevelFile
+
+	path := filepath.Join(t.TempDir(), "replay.json")
+	if err := replay.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadReplay(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(replay, loaded) {
+		t.Fatalf("loaded replay %+v, want %+v", loaded, replay)
+	}
+
+	// 再生した各ステップのボタンが記録と同じ
+	input := NewReplayInput(loaded)
+	step := 0
+	for _, part := range replayScript {
+		scripted := &ScriptedInput{}
+		scripted.Hold(part.buttons...)
+		want := buttonsToStep(scripted.ButtonDown)
+		for i := 0; i < part.frames; i++ {
+			input.Update()
+			if got := buttonsToStep(input.ButtonDown); got != want {
+				t.Errorf("step %d: buttons %b, want %b", step, got, want)
+			}
+			step++
+		}
+	}
+	if !input.Finished() {
+		t.Errorf("replay not finished after %d steps", step)
+	}
+	// 最後のステップの後は全てのボタンを離す
+	input.Update()
+	if got := buttonsToStep(input.ButtonDown); got != 0 {
+		t.Errorf("after the last step: buttons %b, want none", got)
+	}
+}
+
+func TestReplayPlayReproducesRun(t *testing.T) {
+	replay := recordScript(replayScript)
+	replay.Seed = pitSeed
+	path := filepath.Join(t.TempDir(), "replay.json")
+	if err := replay.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadReplay(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// スクリプトで直接操作した結果
+	sim := newTestSimulation(pitSeed, "")
+	for _, part := range replayScript {
+		sim.Run(part.frames, part.buttons...)
+	}
+	want := sim.PlayerPosition()
+
+	// 読み込んだリプレイを、記録されたシードとコースファイルで再生した結果
+	sim = newTestSimulation(loaded.Seed, loaded.LevelFile)
+	sim.Play(loaded)
+	if got := sim.PlayerPosition(); got != want {
+		t.Errorf("replayed position %v, want %v", got, want)
+	}
+	if want.X == 0 {
+		t.Errorf("the player did not move during the script")
+	}
+}
@@ -0,0 +1,182 @@
+package systems
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/EngoEngine/engo"
+	"golang.org/x/image/font/gofont/gosmallcaps"
+)
+
+const (
+	// SimulationWidth : シミュレーションの画面の幅
+	SimulationWidth = 480
+	// SimulationHeight : シミュレーションの画面の高さ
+	SimulationHeight = 320
+)
+
+// SimulationOptions are the options of a Simulation
+type SimulationOptions struct {
+	// AssetsRoot : assetsフォルダのパス（空の場合は"assets"）
+	AssetsRoot string
+	// Seed : コース生成のシード（0の場合はランダム）
+	Seed int64
+	// LevelFile : 最初のコース（1-1）のコースファイル（空の場合はランダムなコース）
+	LevelFile string
+}
+
+// Simulation runs the game without a window and steps the systems with scripted inputs,
+// so that gameplay can be checked automatically, e.g. in go test scenarios:
+//
+//	sim := systems.NewSimulation(systems.SimulationOptions{AssetsRoot: "../assets", Seed: 1})
+//	sim.Start()
+//	sim.Run(300, systems.ButtonMoveRight)
+//	if !sim.GameOver() { ... }
+type Simulation struct {
+	world  *FixedStepWorld
+	player *PlayerSystem
+	game   *GameState
+	// スクリプトの入力
+	input *ScriptedInput
+	// スコア、コイン、残り人数
+	progress *Progress
+	// 経過フレーム数
+	frame int
+}
+
+// simulationScene is the scene of a Simulation
+type simulationScene struct {
+	// 現在のコース
+	course     *GameCourse
+	simulation *Simulation
+}
+
+// Type uniquely defines your game type
+func (*simulationScene) Type() string { return "Simulation" }
+
+// Preload is called before loading any assets from the disk,
+// to allow you to register / queue them
+func (scene *simulationScene) Preload() {
+	LoadGameFiles(scene.course.CourseLevelFile)
+}
+
+// Setup is called before the main loop starts.
+// It adds the same systems as the game.
+func (scene *simulationScene) Setup(u engo.Updater) {
+	engo.Files.LoadReaderData("go.ttf", bytes.NewReader(gosmallcaps.TTF))
+
+	sim := scene.simulation
+	sim.world, _ = u.(*FixedStepWorld)
+	sim.game = scene.course.NewGameState(sim.progress)
+	sim.player = AddGameSystems(sim.world, sim.game, sim.input, scene.course.CourseSeed, scene.course.CourseLevelFile)
+
+	// コースのやり直し（Worldを作り直す）
+	ListenRestart(sim.world, scene, scene.course, sim.progress)
+}
+
+// NewSimulation creates the course and the systems of the game in headless mode.
+// Only one Simulation can be used at a time, as engo is global.
+func NewSimulation(options SimulationOptions) *Simulation {
+	sim := &Simulation{input: &ScriptedInput{}, progress: NewProgress()}
+	// 新しいコースのシードも最初のシードから決める
+	if options.Seed == 0 {
+		options.Seed = time.Now().UnixNano()
+	}
+	engo.Run(engo.RunOptions{
+		HeadlessMode: true,
+		NoRun:        true,
+		AssetsRoot:   options.AssetsRoot,
+		Width:        SimulationWidth,
+		Height:       SimulationHeight,
+		Update:       &FixedStepWorld{},
+	}, &simulationScene{course: NewGameCourse(options.Seed, options.LevelFile), simulation: sim})
+	return sim
+}
+
+// Hold sets the buttons pressed during the next frames. The other buttons are released
+func (sim *Simulation) Hold(buttons ...string) {
+	sim.input.Hold(buttons...)
+}
+
+// Step runs the systems for the number of frames
+func (sim *Simulation) Step(frames int) {
+	for i := 0; i < frames; i++ {
+		sim.world.Update(StepTime)
+		sim.frame++
+	}
+}
+
+// Run holds the buttons during the number of frames, then releases them
+func (sim *Simulation) Run(frames int, buttons ...string) {
+	sim.Hold(buttons...)
+	sim.Step(frames)
+	sim.Hold()
+}
+
+// Start presses Enter on the title to start the game
+func (sim *Simulation) Start() {
+	sim.Run(1, ButtonEnter)
+}
+
+// Frame returns the number of frames run
+func (sim *Simulation) Frame() int {
+	return sim.frame
+}
+
+// Level returns the course of the simulation
+func (sim *Simulation) Level() *Level {
+	return sim.game.Level
+}
+
+// PlayerPosition returns the position of the player
+func (sim *Simulation) PlayerPosition() engo.Point {
+	return sim.player.playerEntity.SpaceComponent.Position
+}
+
+// State returns the state of the game
+func (sim *Simulation) State() int {
+	return sim.game.State()
+}
+
+// Progress returns the score, the coins and the lives
+func (sim *Simulation) Progress() *Progress {
+	return sim.progress
+}
+
+// Course returns the course being played
+func (sim *Simulation) Course() Course {
+	return Courses[sim.progress.Course]
+}
+
+// Time returns the time left on the course in seconds
+func (sim *Simulation) Time() float32 {
+	return sim.game.Time()
+}
+
+// GameOver returns whether the game is over (dead or goal reached)
+func (sim *Simulation) GameOver() bool {
+	switch sim.game.State() {
+	case StateDying, StateGameOver, StateClear, StateGoal:
+		return true
+	}
+	return false
+}
+
+// Goal returns whether the player reached the goal (grabbed the flagpole)
+func (sim *Simulation) Goal() bool {
+	switch sim.game.State() {
+	case StateClear, StateGoal:
+		return true
+	}
+	return false
+}
+
+// Play holds the buttons of every step of the replay, then releases them.
+// The simulation must be created with the seed and the level file of the replay.
+func (sim *Simulation) Play(replay *Replay) {
+	for _, step := range replay.Steps {
+		sim.Hold(stepButtons(step)...)
+		sim.Step(1)
+	}
+	sim.Hold()
+}
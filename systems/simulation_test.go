@@ -0,0 +1,148 @@
+package systems
+
+import (
+	"testing"
+)
+
+const (
+	// pitSeed : 最初の障害物が落とし穴（タイル12）で、その手前に土管も歩く敵キャラもないコースのシード
+	pitSeed = 14
+	// flatLevelFile : 落とし穴も土管も敵キャラもない短いコース（中間地点はタイル12）
+	flatLevelFile = "levels/flat.json"
+)
+
+// newTestSimulation creates a Simulation with the assets of the repository
+func newTestSimulation(seed int64, levelFile string) *Simulation {
+	return NewSimulation(SimulationOptions{AssetsRoot: "../assets", Seed: seed, LevelFile: levelFile})
+}
+
+// runUntil holds the buttons until done returns true or the number of frames is run
+func runUntil(sim *Simulation, frames int, done func() bool, buttons ...string) {
+	sim.Hold(buttons...)
+	for i := 0; i < frames && !done(); i++ {
+		sim.Step(1)
+	}
+	sim.Hold()
+}
+
+func TestSimulationPlayerMoves(t *testing.T) {
+	sim := newTestSimulation(pitSeed, "")
+	sim.Start()
+	start := sim.PlayerPosition()
+
+	sim.Run(30, ButtonMoveRight)
+	if p := sim.PlayerPosition(); p.X <= start.X {
+		t.Errorf("after moving right: x = %v, want more than %v", p.X, start.X)
+	}
+	if sim.GameOver() {
+		t.Errorf("game over after 30 frames")
+	}
+}
+
+func TestSimulationFallIntoPit(t *testing.T) {
+	sim := newTestSimulation(pitSeed, "")
+	sim.Start()
+
+	// ジャンプせずに右に進み続けると落とし穴に落ちる
+	runUntil(sim, 600, sim.GameOver, ButtonMoveRight)
+	if !sim.GameOver() {
+		t.Fatalf("not game over after 600 frames, player at %v", sim.PlayerPosition())
+	}
+	if sim.Goal() {
+		t.Errorf("goal reached instead of falling")
+	}
+	if p := sim.PlayerPosition(); p.Y <= sim.player.playerEntity.playerPositionY {
+		t.Errorf("player y = %v, want below the ground (%v)", p.Y, sim.player.playerEntity.playerPositionY)
+	}
+}
+
+func TestSimulationGoal(t *testing.T) {
+	sim := newTestSimulation(1, flatLevelFile)
+	sim.Start()
+
+	runUntil(sim, 600, sim.Goal, ButtonMoveRight)
+	if !sim.Goal() {
+		t.Fatalf("goal not reached after 600 frames, player at %v", sim.PlayerPosition())
+	}
+}
+
+// jumpTop returns the highest position of the player (smallest y) during a jump holding the button for the frames
+func jumpTop(sim *Simulation, frames int) float32 {
+	top := sim.PlayerPosition().Y
+	sim.Hold(ButtonJump)
+	for i := 0; i < 60; i++ {
+		if i == frames {
+			sim.Hold()
+		}
+		sim.Step(1)
+		if y := sim.PlayerPosition().Y; y < top {
+			top = y
+		}
+	}
+	sim.Hold()
+	return top
+}
+
+func TestSimulationJumpCut(t *testing.T) {
+	sim := newTestSimulation(1, flatLevelFile)
+	sim.Start()
+	ground := sim.PlayerPosition().Y
+
+	// ジャンプボタンを長く押すほど高く跳ぶ
+	short := ground - jumpTop(sim, 1)
+	long := ground - jumpTop(sim, 30)
+	if short <= 0 || long <= short {
+		t.Errorf("jump heights %vpx (short press), %vpx (long press): want a lower short jump", short, long)
+	}
+	if y := sim.PlayerPosition().Y; y != ground {
+		t.Errorf("player at y %v after the jumps, want on the ground (%v)", y, ground)
+	}
+}
+
+func TestSimulationLeftBound(t *testing.T) {
+	sim := newTestSimulation(1, flatLevelFile)
+	sim.Start()
+	start := sim.PlayerPosition()
+
+	// スタート地点では左に進んでも画面の外に出ない
+	sim.Run(60, ButtonMoveLeft)
+	if p := sim.PlayerPosition(); p.X >= start.X || p.X < -ExtraSizeX {
+		t.Errorf("after moving left from the start: x = %v, want between %v and %v", p.X, -ExtraSizeX, start.X)
+	}
+	// 右に進んだ後は画面の左端で止まる（カメラは戻らない）
+	sim.Run(90, ButtonMoveRight)
+	camera := sim.player.playerEntity.cameraPositionX
+	if camera <= SimulationWidth/2 {
+		t.Fatalf("camera at %v after moving right, want scrolled", camera)
+	}
+	sim.Run(120, ButtonMoveLeft)
+	if got := sim.player.playerEntity.cameraPositionX; got != camera {
+		t.Errorf("camera at %v after moving left, want %v", got, camera)
+	}
+	if want := camera - SimulationWidth/2 - ExtraSizeX; sim.PlayerPosition().X != want {
+		t.Errorf("after moving left: x = %v, want the left of the screen %v", sim.PlayerPosition().X, want)
+	}
+}
+
+func TestSimulationsDoNotShareState(t *testing.T) {
+	pit := newTestSimulation(pitSeed, "")
+	pit.Start()
+	flat := newTestSimulation(1, flatLevelFile)
+	flat.Start()
+
+	// 交互に進めても、それぞれのコースとコリジョンマップで動く
+	runUntil(pit, 600, pit.GameOver, ButtonMoveRight)
+	flat.Run(60, ButtonMoveRight)
+	if pit.game == flat.game || pit.game.Level == flat.game.Level || pit.game.CollisionMap == flat.game.CollisionMap {
+		t.Fatalf("the simulations share the state of the game")
+	}
+	if !pit.GameOver() {
+		t.Errorf("not game over in the course with a pit")
+	}
+	if flat.GameOver() {
+		t.Errorf("game over in the flat course, player at %v", flat.PlayerPosition())
+	}
+	if got, want := flat.game.Level.Width, 40; got != want {
+		t.Errorf("flat course of width %d, want %d", got, want)
+	}
+}
@@ -2,7 +2,9 @@ package systems
 
 import (
 	"fmt"
+	"image/color"
 	"math/rand"
+	"time"
 
 	"github.com/EngoEngine/ecs"
 	"github.com/EngoEngine/engo"
@@ -40,34 +42,37 @@ const (
 	MountSpriteSheetCell = 11
 	// PipeSpriteSheetCell : スプライトシートで使用する土管のセル番号
 	PipeSpriteSheetCell = 3
+	// CastleGroundSpriteSheetCell : 城のスプライトシートで使用する地面（レンガ）のセル番号
+	CastleGroundSpriteSheetCell = 20
 )
 
 var tileFile = "./Mario/Tilesets/OverWorld.png"
 var castleFile = "./Mario/Tilesets/Castle.png"
 
-// FallPoint : 落とし穴の位置
-var FallPoint []int
-
-// MountPoint : 山の位置
-var MountPoint []int
+// mountPositionY returns the Y position of the mountains
+func mountPositionY() float32 {
+	return engo.WindowHeight() - CellHeight16*7
+}
 
-// PipePoint : 土管の位置
-var PipePoint []int
+// pipePositionY returns the Y position of the pipes
+func pipePositionY() float32 {
+	return engo.WindowHeight() - CellHeight16*6
+}
 
-// makingxxxx：作成状態（0:作成中でない 1:作成開始 2：それ以外）
-var makingFall int
-var makingCloud int
-var makingMount int
-var makingPipe int
+// groundPositionY returns the Y position of the characters standing on the ground
+func groundPositionY() float32 {
+	return engo.WindowHeight() - CellHeight16*6
+}
 
-// addCell：セル追加値
-var addCell int
+// blockPositionY returns the Y position of a block at the height (tiles above the ground)
+func blockPositionY(height int) float32 {
+	return engo.WindowHeight() - float32(CellHeight16*(TileDepth+height))
+}
 
-// Y値
-var mountPositionY float32
-var pipePositionY float32
-var onPipePositionY float32
-var castleositionY float32
+// castlePositionY returns the Y position of the castle
+func castlePositionY() float32 {
+	return engo.WindowHeight() - CellHeight16*9
+}
 
 // Tile is Eintity for the TileSystem
 type Tile struct {
@@ -76,8 +81,16 @@ type Tile struct {
 	common.SpaceComponent
 }
 
-// TileSystem builds a game background
+// TileSystem builds a game background, in the theme of the course
 type TileSystem struct {
+	// Seed is the seed of the level generation. 0 means a random seed
+	Seed int64
+	// LevelFile is the url of the level file. The course is generated randomly if empty,
+	// with the name and the theme of the current course (Courses)
+	LevelFile string
+	// Game is the state of the game, receiving the loaded course
+	Game *GameState
+
 	world      *ecs.World
 	tileEntity []*Tile
 }
@@ -92,89 +105,159 @@ func (ts *TileSystem) Update(dt float32) {}
 func (ts *TileSystem) New(w *ecs.World) {
 	//　Worldの追加
 	ts.world = w
+
+	// コースの読み込み
+	ts.Game.Level = ts.loadLevel()
+	ts.Game.CollisionMap = ts.buildCollisionMap()
+	// テーマの背景色
+	common.SetBackground(themeBackgrounds[ts.Game.Level.Theme])
+
+	// Tile配列作成
+	var Tiles []*Tile
+	if ts.Game.Level.tmx != nil {
+		Tiles = ts.tmxTiles()
+	} else {
+		Tiles = ts.spriteTiles()
+	}
+
+	// ----------------------- //
+	// ------- 城の作成 ------- //
+	// ----------------------- //
+	tile := &Tile{BasicEntity: ecs.NewBasic()}
+
+	// SpaceComponent
+	tile.SpaceComponent = common.SpaceComponent{
+		Position: engo.Point{X: float32(ts.Game.Level.Castle * CellWidth16), Y: castlePositionY()},
+	}
+
+	// 画像の読み込み
+	texture, err := common.LoadedSprite(castleFile)
+	if err != nil {
+		fmt.Println("Unable to load texture: " + castleFile + "：" + err.Error())
+	}
+	// RenderComponent
+	tile.RenderComponent = common.RenderComponent{
+		Drawable: texture,
+		Scale:    engo.Point{X: 1, Y: 1},
+	}
+	tile.RenderComponent.SetZIndex(0)
+
+	// コンポーネントセット
+	Tiles = append(Tiles, tile)
+
+	// RenderSystemに追加
+	for _, system := range ts.world.Systems() {
+		switch sys := system.(type) {
+		case *common.RenderSystem:
+			for _, v := range Tiles {
+				ts.tileEntity = append(ts.tileEntity, v)
+				sys.Add(&v.BasicEntity, &v.RenderComponent, &v.SpaceComponent)
+			}
+		}
+	}
+}
+
+// buildCollisionMap creates the CollisionMap of the ground, the pipes and the blocks of the course
+func (ts *TileSystem) buildCollisionMap() *CollisionMap {
+	rows := int(engo.WindowHeight()) / CellHeight16
+	collisionMap := NewCollisionMap(ts.Game.Level.Width+1, rows)
+	for i := 0; i <= ts.Game.Level.Width; i++ {
+		if ts.Game.Level.isPit(i) {
+			continue
+		}
+		for j := 0; j < TileDepth; j++ {
+			collisionMap.Set(i, rows-1-j, CellGround)
+		}
+	}
+	for _, i := range ts.Game.Level.Pipes {
+		for j := 0; j < CellWidth32/CellWidth16; j++ {
+			for k := 0; k < CellHeight32/CellHeight16; k++ {
+				collisionMap.Set(i+j, int(pipePositionY())/CellHeight16+k, CellPipe)
+			}
+		}
+	}
+	for _, block := range ts.Game.Level.Blocks {
+		cell := CellBrick
+		if block.Type == BlockQuestion {
+			cell = CellQuestion
+		}
+		collisionMap.Set(block.X, int(blockPositionY(block.Height))/CellHeight16, cell)
+	}
+	return collisionMap
+}
+
+// spriteTiles builds the tiles of the course from the OverWorld sprite sheet
+func (ts *TileSystem) spriteTiles() []*Tile {
 	// スプライトシートの作成
 	Spritesheet16x16 := common.NewSpritesheetWithBorderFromFile(tileFile, CellWidth16, CellHeight16, 0, 0)
 	Spritesheet32x32 := common.NewSpritesheetWithBorderFromFile(tileFile, CellWidth32, CellHeight32, 0, 0)
 	Spritesheet16x64 := common.NewSpritesheetWithBorderFromFile(tileFile, CellWidth16, CellHeight64, 0, 0)
-
-	// 初期化
-	makingFall = 0
-	addCell = 0
-	cloudHeight := 0
-	mountPositionY = engo.WindowHeight() - CellHeight16*7
-	pipePositionY = engo.WindowHeight() - CellHeight16*6
-	onPipePositionY = engo.WindowHeight() - CellHeight16*8
-	castleositionY = engo.WindowHeight() - CellHeight16*9
+	CastleSpritesheet16x16 := common.NewSpritesheetWithBorderFromFile(castleFile, CellWidth16, CellHeight16, 0, 0)
+
+	// テーマ毎の地面（地下は地上の地面を青く塗る、城は城のレンガを使う）
+	theme := ts.Game.Level.Theme
+	ground := Spritesheet16x16.Cell(GroundSpriteSheetCell)
+	groundColor := color.Color(color.White)
+	switch theme {
+	case ThemeUnderground:
+		groundColor = undergroundColor
+	case ThemeCastle:
+		ground = CastleSpritesheet16x16.Cell(CastleGroundSpriteSheetCell)
+	}
 
 	// Tile配列作成
 	Tiles := make([]*Tile, 0)
 
-	for i := 0; i <= TileNum; i++ {
-		// ----------------------- //
-		// ------- 地面の作成 ------ //
-		// ----------------------- //
-		// Start付近とGoal付近に落とし穴は作らない
-		if i >= 10 && i < TileNum-AroundGoalTileNum {
-			randomNum := rand.Intn(10)
-			if randomNum == 0 {
-				makingFall = 1
-			} else {
-				// 最低タイル2つ分は落とし穴を作成する
-				if makingFall == 1 {
-					makingFall = 2
-				} else {
-					makingFall = 0
-				}
-			}
+	// ----------------------- //
+	// ------- 地面の作成 ------ //
+	// ----------------------- //
+	for i := 0; i <= ts.Game.Level.Width; i++ {
+		if ts.Game.Level.isPit(i) {
+			continue
 		}
-		if makingFall != 0 {
-			for j := 0; j < CellWidth16; j++ {
-				// 落とし穴の位置記録
-				FallPoint = append(FallPoint, i*CellWidth16+j)
+		for j := 0; j < TileDepth; j++ {
+			tile := &Tile{BasicEntity: ecs.NewBasic()}
+
+			// SpaceComponent
+			tile.SpaceComponent = common.SpaceComponent{
+				Position: engo.Point{X: float32(i * CellWidth16), Y: float32(int(engo.WindowHeight()) - (j+1)*CellHeight16)},
 			}
-		} else {
-			for j := 0; j < TileDepth; j++ {
-				tile := &Tile{BasicEntity: ecs.NewBasic()}
-
-				// SpaceComponent
-				tile.SpaceComponent = common.SpaceComponent{
-					Position: engo.Point{X: float32(i * CellWidth16), Y: float32(int(engo.WindowHeight()) - (j+1)*CellHeight16)},
-				}
-				// RenderComponent
-				tile.RenderComponent = common.RenderComponent{
-					Drawable: Spritesheet16x16.Cell(GroundSpriteSheetCell),
-					Scale:    engo.Point{X: 1, Y: 1},
-				}
-				tile.RenderComponent.SetZIndex(0)
-
-				// コンポーネントセット
-				Tiles = append(Tiles, tile)
+			// RenderComponent
+			tile.RenderComponent = common.RenderComponent{
+				Drawable: ground,
+				Color:    groundColor,
+				Scale:    engo.Point{X: 1, Y: 1},
 			}
+			tile.RenderComponent.SetZIndex(0)
 
+			// コンポーネントセット
+			Tiles = append(Tiles, tile)
 		}
-		// ----------------------- //
-		// ------- 雲の作成 ------- //
-		// ----------------------- //
-		if makingCloud == 0 {
-			randomNum := rand.Intn(12)
-			if randomNum < 3 {
-				makingCloud = 1
-				cloudHeight = randomNum
-			}
-		}
-		if makingCloud != 0 {
+	}
+	// 雲と山は地上のみ
+	if theme != ThemeOverworld {
+		return append(Tiles, ts.pipeTiles(Spritesheet32x32)...)
+	}
+	// ----------------------- //
+	// ------- 雲の作成 ------- //
+	// ----------------------- //
+	for _, cloud := range ts.Game.Level.Clouds {
+		for j := 0; j < CloudTileNum; j++ {
 			tile := &Tile{BasicEntity: ecs.NewBasic()}
-			j := float32(0)
-			// 2つ目の雲作成中の場合
-			if makingCloud > 2 {
-				j = float32(i) - 0.5
-			} else {
-				j = float32(i)
+			// 3つ目の雲は半分重ねる
+			x := float32(cloud.X + j)
+			if j == CloudTileNum-1 {
+				x -= 0.5
+			}
+			// 2つ目以降は同じセルを使う
+			addCell := 0
+			if j > 0 {
+				addCell = 1
 			}
 
 			// SpaceComponent
 			tile.SpaceComponent = common.SpaceComponent{
-				Position: engo.Point{X: float32(j * CellWidth32), Y: float32(int(engo.WindowHeight()/3) - cloudHeight*CellHeight16)},
+				Position: engo.Point{X: x * CellWidth32, Y: float32(int(engo.WindowHeight()/3) - cloud.Height*CellHeight16)},
 			}
 
 			// RenderComponent
@@ -182,147 +265,129 @@ func (ts *TileSystem) New(w *ecs.World) {
 				Drawable: Spritesheet32x32.Cell(CloudSpriteSheetCell + addCell),
 				Scale:    engo.Point{X: 1, Y: 1},
 			}
-			tile.RenderComponent.SetZIndex(float32(makingCloud))
+			tile.RenderComponent.SetZIndex(float32(j + 1))
 
 			// コンポーネントセット
 			Tiles = append(Tiles, tile)
-
-			switch makingCloud {
-			case 1:
-				makingCloud++
-				addCell = 1
-				break
-			case 2:
-				makingCloud++
-				addCell = 1
-				break
-			default:
-				makingCloud = 0
-				addCell = 0
-				break
-			}
 		}
 	}
-	for i := 0; i <= TileNum; i++ {
-		// ----------------------- //
-		// ------- 山の作成 ------- //
-		// ----------------------- //
-		makingMount = 1
-		for j := 0; j < MountTileNum+2; j++ {
-			// 山を作成できる十分なスペースがない場合
-			if getMakingInfo(FallPoint, (i+j)*CellWidth16) {
-				makingMount = 0
+	// ----------------------- //
+	// ------- 山の作成 ------- //
+	// ----------------------- //
+	for _, i := range ts.Game.Level.Mountains {
+		for j := 0; j < MountTileNum; j++ {
+			tile := &Tile{BasicEntity: ecs.NewBasic()}
+
+			// SpaceComponent
+			tile.SpaceComponent = common.SpaceComponent{
+				Position: engo.Point{X: float32((i + j) * CellWidth16), Y: mountPositionY()},
 			}
-		}
-		if makingMount != 0 && i < TileNum-AroundGoalTileNum {
-			for j := 0; j < MountTileNum; j++ {
-				tile := &Tile{BasicEntity: ecs.NewBasic()}
-
-				// SpaceComponent
-				tile.SpaceComponent = common.SpaceComponent{
-					Position: engo.Point{X: float32((i + j) * CellWidth16), Y: mountPositionY},
-				}
-
-				// RenderComponent
-				tile.RenderComponent = common.RenderComponent{
-					Drawable: Spritesheet16x64.Cell(MountSpriteSheetCell + j),
-					Scale:    engo.Point{X: 1, Y: 1},
-				}
-				tile.RenderComponent.SetZIndex(0)
-
-				// コンポーネントセット
-				Tiles = append(Tiles, tile)
-
-				// 山の位置記録
-				MountPoint = append(MountPoint, (i+j)*CellWidth16)
+
+			// RenderComponent
+			tile.RenderComponent = common.RenderComponent{
+				Drawable: Spritesheet16x64.Cell(MountSpriteSheetCell + j),
+				Scale:    engo.Point{X: 1, Y: 1},
 			}
-			// ランダムな値をインクリメント
-			i = i + 20
+			tile.RenderComponent.SetZIndex(0)
+
+			// コンポーネントセット
+			Tiles = append(Tiles, tile)
 		}
 	}
-	for i := 0; i <= TileNum; i++ {
-		// ------------------------ //
-		// ------- 土管の作成 ------- //
-		// ------------------------ //
-		makingPipe = 1
-		for j := 0; j < PipeTileNum+2; j++ {
-			// 土管を作成できる十分なスペースがない、もしくは山を作成している場合
-			if getMakingInfo(FallPoint, (i+j)*CellWidth16) || getMakingInfo(MountPoint, (i+j)*CellWidth16) {
-				makingPipe = 0
-			}
+	return append(Tiles, ts.pipeTiles(Spritesheet32x32)...)
+}
+
+// pipeTiles builds the tiles of the pipes of the course
+func (ts *TileSystem) pipeTiles(Spritesheet32x32 *common.Spritesheet) []*Tile {
+	// Tile配列作成
+	Tiles := make([]*Tile, 0)
+
+	// ------------------------ //
+	// ------- 土管の作成 ------- //
+	// ------------------------ //
+	for _, i := range ts.Game.Level.Pipes {
+		tile := &Tile{BasicEntity: ecs.NewBasic()}
+
+		// SpaceComponent
+		tile.SpaceComponent = common.SpaceComponent{
+			Position: engo.Point{X: float32(i * CellWidth16), Y: pipePositionY()},
 		}
-		// Start付近とGoal付近は土管は作らない
-		if i >= 10 && i < TileNum-AroundGoalTileNum {
-			if makingPipe != 0 {
-				tile := &Tile{BasicEntity: ecs.NewBasic()}
-
-				// SpaceComponent
-				tile.SpaceComponent = common.SpaceComponent{
-					Position: engo.Point{X: float32(i * CellWidth16), Y: pipePositionY},
-				}
-
-				// RenderComponent
-				tile.RenderComponent = common.RenderComponent{
-					Drawable: Spritesheet32x32.Cell(PipeSpriteSheetCell),
-					Scale:    engo.Point{X: 1, Y: 1},
-				}
-				tile.RenderComponent.SetZIndex(7)
-
-				// コンポーネントセット
-				Tiles = append(Tiles, tile)
-
-				// 土管の位置記録
-				for j := 0; j < CellWidth32; j++ {
-					PipePoint = append(PipePoint, i*CellWidth16+j)
-				}
-				// ランダムな値をインクリメント
-				i = i + 30
-			}
+
+		// RenderComponent
+		tile.RenderComponent = common.RenderComponent{
+			Drawable: Spritesheet32x32.Cell(PipeSpriteSheetCell),
+			Scale:    engo.Point{X: 1, Y: 1},
 		}
-	}
-	// ----------------------- //
-	// ------- 城の作成 ------- //
-	// ----------------------- //
-	tile := &Tile{BasicEntity: ecs.NewBasic()}
+		tile.RenderComponent.SetZIndex(7)
 
-	// SpaceComponent
-	tile.SpaceComponent = common.SpaceComponent{
-		Position: engo.Point{X: float32((TileNum - GoalTileNum) * CellWidth16), Y: castleositionY},
+		// コンポーネントセット
+		Tiles = append(Tiles, tile)
 	}
+	return Tiles
+}
 
-	// 画像の読み込み
-	texture, err := common.LoadedSprite(castleFile)
-	if err != nil {
-		fmt.Println("Unable to load texture: " + castleFile + "：" + err.Error())
-	}
-	// RenderComponent
-	tile.RenderComponent = common.RenderComponent{
-		Drawable: texture,
-		Scale:    engo.Point{X: 1, Y: 1},
-	}
-	tile.RenderComponent.SetZIndex(0)
+// tmxTiles builds the tiles of the course from the tile layers of the Tiled map
+func (ts *TileSystem) tmxTiles() []*Tile {
+	// マップの下端を画面の下端に合わせる
+	offsetY := engo.WindowHeight() - float32(ts.Game.Level.tmx.Height()*ts.Game.Level.tmx.TileHeight)
 
-	// コンポーネントセット
-	Tiles = append(Tiles, tile)
+	// Tile配列作成
+	Tiles := make([]*Tile, 0)
 
-	// RenderSystemに追加
-	for _, system := range ts.world.Systems() {
-		switch sys := system.(type) {
-		case *common.RenderSystem:
-			for _, v := range Tiles {
-				ts.tileEntity = append(ts.tileEntity, v)
-				sys.Add(&v.BasicEntity, &v.RenderComponent, &v.SpaceComponent)
+	for _, layer := range ts.Game.Level.tmx.TileLayers {
+		// 土管はパックンフラワーより手前に、背景は地面や城より奥に表示
+		zIndex := float32(0)
+		switch layer.Name {
+		case TMXLayerPipes:
+			zIndex = 7
+		case TMXLayerBackground:
+			zIndex = -1
+		}
+		for _, v := range layer.Tiles {
+			if v.Image == nil || v.Image.Width() == 0 {
+				continue
+			}
+			tile := &Tile{BasicEntity: ecs.NewBasic()}
+
+			// SpaceComponent
+			tile.SpaceComponent = common.SpaceComponent{
+				Position: engo.Point{X: v.X, Y: v.Y + offsetY},
 			}
+
+			// RenderComponent
+			tile.RenderComponent = common.RenderComponent{
+				Drawable: v.Image,
+				Scale:    engo.Point{X: 1, Y: 1},
+			}
+			tile.RenderComponent.SetZIndex(zIndex)
+
+			// コンポーネントセット
+			Tiles = append(Tiles, tile)
 		}
 	}
+	return Tiles
 }
 
-// getMakingInfo ： 対象位置に含まれているか
-func getMakingInfo(s []int, e int) bool {
-	for _, v := range s {
-		if e == v {
-			return true
+// loadLevel returns the course from the level file (.json or .tmx), or a random course if there is no level file.
+// The scene checks the level file before starting, so a course which fails to load is never replaced
+func (ts *TileSystem) loadLevel() *Level {
+	if ts.LevelFile != "" {
+		level, err := LoadLevelFile(ts.LevelFile)
+		if err == nil {
+			fmt.Println("Level:", ts.LevelFile)
+			return level
 		}
+		fmt.Println("Unable to load level: " + ts.LevelFile + "：" + err.Error())
+	}
+	// シード設定
+	if ts.Seed == 0 {
+		ts.Seed = time.Now().UnixNano()
 	}
-	return false
+	fmt.Println("Seed:", ts.Seed)
+	level := generateLevel(rand.New(rand.NewSource(ts.Seed)))
+	// 現在のコースの名前とテーマ
+	course := Courses[ts.Game.Progress.Course]
+	level.Name = course.Name
+	level.Theme = course.Theme
+	return level
 }